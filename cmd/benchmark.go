@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// BENCHMARK_COLUMNS lists the columns read from the GitHub Innovation Graph programming languages CSV.
+var BENCHMARK_COLUMNS = []string{"num_pushers", "language", "iso2_code", "year", "quarter"}
+
+// BENCHMARK_INDEX_TOLERANCE is how far (in index points) a language can be from the benchmark before it is
+// reported as over- or under-indexed.
+const BENCHMARK_INDEX_TOLERANCE = 10
+
+// BENCHMARK_QUARTERS is the number of most recent quarters in the file used to benchmark the count command.
+const BENCHMARK_QUARTERS = 4
+
+var benchmark_flag string
+var benchmark_economy_flag string
+
+// addBenchmarkFlags registers the --benchmark and --benchmark-economy flags on a command.
+func addBenchmarkFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&benchmark_flag, "benchmark", "", "Path to the GitHub Innovation Graph programming languages CSV (languages.csv) to compare language shares against")
+	cmd.Flags().StringVar(&benchmark_economy_flag, "benchmark-economy", "", "An ISO 3166-1 alpha-2 economy code (e.g., US) to benchmark against instead of the global totals")
+}
+
+// Benchmark holds the number of developers pushing code in each language per quarter, from the GitHub Innovation Graph.
+// Quarters are periods of the quarter interval (year*4+quarter-1).
+type Benchmark struct {
+	Economy string
+	// Pushers maps each quarter to the number of pushers per language.
+	Pushers map[int]map[string]int
+	// Totals holds the number of pushers across all languages per quarter.
+	Totals map[int]int
+}
+
+// LoadBenchmark reads the Innovation Graph languages CSV. Rows are summed across economies unless an economy is given.
+func LoadBenchmark(path, economy string) (*Benchmark, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read benchmark: %v", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse benchmark '%s': %v", path, err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range BENCHMARK_COLUMNS {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("benchmark '%s' is missing the '%s' column. Expected the Innovation Graph languages.csv with columns: %s", path, name, strings.Join(BENCHMARK_COLUMNS, ", "))
+		}
+	}
+
+	benchmark := &Benchmark{
+		Economy: strings.ToUpper(economy),
+		Pushers: make(map[int]map[string]int),
+		Totals:  make(map[int]int),
+	}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse benchmark '%s': %v", path, err)
+		}
+		if economy != "" && !strings.EqualFold(record[columns["iso2_code"]], economy) {
+			continue
+		}
+
+		pushers, errPushers := strconv.Atoi(record[columns["num_pushers"]])
+		year, errYear := strconv.Atoi(record[columns["year"]])
+		quarter, errQuarter := strconv.Atoi(record[columns["quarter"]])
+		if errPushers != nil || errYear != nil || errQuarter != nil || quarter < 1 || quarter > 4 {
+			return nil, fmt.Errorf("invalid row on line %d of benchmark '%s'", line, path)
+		}
+
+		period := year*4 + quarter - 1
+		if benchmark.Pushers[period] == nil {
+			benchmark.Pushers[period] = make(map[string]int)
+		}
+		benchmark.Pushers[period][record[columns["language"]]] += pushers
+		benchmark.Totals[period] += pushers
+	}
+
+	if len(benchmark.Totals) == 0 {
+		if economy != "" {
+			return nil, fmt.Errorf("benchmark '%s' has no rows for economy '%s'", path, economy)
+		}
+		return nil, fmt.Errorf("benchmark '%s' has no rows", path)
+	}
+	return benchmark, nil
+}
+
+// Name describes the benchmark for titles (e.g., Global or US).
+func (b *Benchmark) Name() string {
+	if b.Economy == "" {
+		return "Global"
+	}
+	return b.Economy
+}
+
+// LatestQuarter returns the most recent quarter in the benchmark.
+func (b *Benchmark) LatestQuarter() int {
+	var latest int
+	for quarter := range b.Totals {
+		if quarter > latest {
+			latest = quarter
+		}
+	}
+	return latest
+}
+
+// Share returns a language's percentage of pushers over a range of quarters. It returns false if the
+// benchmark has no data for the range.
+func (b *Benchmark) Share(lang string, firstQuarter, lastQuarter int) (float64, bool) {
+	var pushers, total int
+	for quarter := firstQuarter; quarter <= lastQuarter; quarter++ {
+		pushers += b.Pushers[quarter][lang]
+		total += b.Totals[quarter]
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return float64(pushers) / float64(total) * 100, true
+}
+
+// PeriodQuarters returns the quarters covered by a trend period. A month maps to the quarter containing it.
+func PeriodQuarters(period int, interval string) (int, int) {
+	first := periodOf(periodStart(period, interval), "quarter")
+	last := periodOf(periodStart(period+1, interval).AddDate(0, 0, -1), "quarter")
+	return first, last
+}
+
+// LanguageMixTotal returns the sum of the counts (or bytes) of all languages, before any top-N cut. A language's share
+// of this total is comparable with its share of the benchmark: both sum to 100% across languages, whereas shares of
+// repositories do not, since a repository using several languages counts toward each of them.
+func LanguageMixTotal(languageData map[string]int) int {
+	var total int
+	for _, value := range languageData {
+		total += value
+	}
+	return total
+}
+
+// BenchmarkBasis describes the basis on which language shares are compared with the benchmark, for the run header.
+func BenchmarkBasis(metric string) string {
+	if metric == "bytes" {
+		return "Index basis: each language's share of the bytes of all languages (Mix Share) vs. its share of pushers"
+	}
+	return "Index basis: each language's count over the sum of the counts of all languages (Mix Share) vs. its share of pushers"
+}
+
+// benchmarkColumns returns a language's share of the language mix, its benchmark share and the over/under index.
+// The index is 100 when the share matches the benchmark, above 100 when over-indexed and below when under-indexed.
+func (b *Benchmark) benchmarkColumns(lang string, value, mixTotal, firstQuarter, lastQuarter int) (string, string, string) {
+	share := periodShare(value, mixTotal)
+	mixShare := fmt.Sprintf("%.1f%%", share)
+	benchmarkShare, ok := b.Share(lang, firstQuarter, lastQuarter)
+	if !ok {
+		return mixShare, "n/a", ""
+	}
+	if benchmarkShare == 0 {
+		return mixShare, "0.0%", ""
+	}
+	index := share / benchmarkShare * 100
+	formatted := fmt.Sprintf("%.0f", index)
+	switch {
+	case index > 100+BENCHMARK_INDEX_TOLERANCE:
+		formatted = pterm.Green("▲ " + formatted)
+	case index < 100-BENCHMARK_INDEX_TOLERANCE:
+		formatted = pterm.Red("▼ " + formatted)
+	default:
+		formatted = pterm.Gray("● " + formatted)
+	}
+	return mixShare, fmt.Sprintf("%.1f%%", benchmarkShare), formatted
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// codeqlLanguageAliases maps the language identifiers found in older configurations and analysis categories
+// (e.g., /language:javascript) to their current extractor.
+var codeqlLanguageAliases = map[string]string{
+	"c":          "c-cpp",
+	"cpp":        "c-cpp",
+	"java":       "java-kotlin",
+	"kotlin":     "java-kotlin",
+	"javascript": "javascript-typescript",
+	"typescript": "javascript-typescript",
+}
+
+// CodeQLExtractor returns the CodeQL extractor for a Linguist language, if the CodeQL version in use supports it.
+func CodeQLExtractor(lang string) (string, bool) {
+	extractor, ok := GetCodeQLSupport().Languages[lang]
+	return extractor, ok
+}
+
+// NormalizeCodeQLLanguage maps a CodeQL language identifier from code scanning to its extractor.
+func NormalizeCodeQLLanguage(id string) string {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if extractor, ok := codeqlLanguageAliases[id]; ok {
+		return extractor
+	}
+	return id
+}
+
+// CODEQL_ACTIONS_EXTRACTOR is the CodeQL extractor for GitHub Actions workflows, which Linguist does not report
+// as a repository language.
+const CODEQL_ACTIONS_EXTRACTOR = "actions"
+
+// CODEQL_EXTRACTOR_ORDER lists the CodeQL extractors in the order they are reported.
+var CODEQL_EXTRACTOR_ORDER = []string{"javascript-typescript", "java-kotlin", "c-cpp", "csharp", "go", "python", "ruby", "swift", "rust", CODEQL_ACTIONS_EXTRACTOR}
+
+// CODEQL_COMPILED_EXTRACTORS maps the extractors of compiled languages to a note on what their rollout may need.
+// Default setup analyzes them without building where it can, but custom builds may need advanced setup.
+var CODEQL_COMPILED_EXTRACTORS = map[string]string{
+	"c-cpp":       "may need advanced setup for custom builds",
+	"csharp":      "may need advanced setup for custom builds",
+	"go":          "may need advanced setup for custom builds",
+	"java-kotlin": "may need advanced setup for custom builds",
+	"swift":       "needs macOS runners",
+}
+
+// CODEQL_BY_OPTIONS lists the values supported by --codeql-by.
+var CODEQL_BY_OPTIONS = []string{"language", "extractor"}
+
+var codeql_by_flag string
+
+// addCodeQLByFlag registers the --codeql-by flag on a command.
+func addCodeQLByFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&codeql_by_flag, "codeql-by", "language", "Report CodeQL-supported languages by Linguist language or by CodeQL extractor (language, extractor), requires --codeql")
+}
+
+// ParseCodeQLBy validates the --codeql-by flag and reports whether to report by CodeQL extractor.
+func ParseCodeQLBy(codeqlBy string, codeql bool) (bool, error) {
+	codeqlBy = strings.ToLower(strings.TrimSpace(codeqlBy))
+	if !MatchesLanguageFilter(codeqlBy, CODEQL_BY_OPTIONS) {
+		return false, fmt.Errorf("invalid --codeql-by value specified. Options are: %s", strings.Join(CODEQL_BY_OPTIONS, ", "))
+	}
+	if codeqlBy == "extractor" && !codeql {
+		return false, fmt.Errorf("--codeql-by extractor requires the --codeql flag (or --preset codeql)")
+	}
+	return codeqlBy == "extractor", nil
+}
+
+// CodeQLExtractorSet returns the CodeQL extractors that analyze a set of languages.
+func CodeQLExtractorSet(languages map[string]struct{}) map[string]struct{} {
+	extractors := make(map[string]struct{})
+	for lang := range languages {
+		if extractor, ok := CodeQLExtractor(lang); ok {
+			extractors[extractor] = struct{}{}
+		}
+	}
+	return extractors
+}
+
+// CodeQLExtractorSizes sums the sizes of languages per CodeQL extractor. Languages that CodeQL does not support are dropped.
+func CodeQLExtractorSizes(languages map[string]int) map[string]int {
+	extractors := make(map[string]int)
+	for lang, size := range languages {
+		if extractor, ok := CodeQLExtractor(lang); ok {
+			extractors[extractor] += size
+		}
+	}
+	return extractors
+}
+
+// RenderExtractorHistogram renders how many repositories are analyzed by each number of CodeQL extractors.
+func RenderExtractorHistogram(histogram map[int]int, totalRepos int) {
+	buckets := make([]int, 0, len(histogram))
+	for bucket := range histogram {
+		buckets = append(buckets, bucket)
+	}
+	sort.Ints(buckets)
+
+	pterm.Println()
+	pterm.DefaultSection.Println("CodeQL Extractors per Repo")
+	rows := [][]string{{"Extractors", "Repos", "Percentage"}}
+	for _, bucket := range buckets {
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", bucket),
+			fmt.Sprintf("%d", histogram[bucket]),
+			FormatPercentage(histogram[bucket], totalRepos, false),
+		})
+	}
+	pterm.DefaultTable.WithHasHeader(true).WithData(rows).Render()
+}
@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed codeql.yml
+var codeqlCatalogYAML []byte
+
+var codeql_version_flag string
+var codeql_catalog_flag string
+
+// CodeQLCatalog lists the languages supported by each CodeQL release and the CodeQL version bundled with each
+// GitHub Enterprise Server release.
+type CodeQLCatalog struct {
+	Releases []CodeQLRelease   `yaml:"releases"`
+	GHES     map[string]string `yaml:"ghes"`
+}
+
+// CodeQLRelease is a CodeQL release with the languages it added support for.
+type CodeQLRelease struct {
+	Version string `yaml:"version"`
+	// Languages maps the Linguist languages added by the release to their CodeQL extractor.
+	Languages map[string]string `yaml:"languages"`
+	// Extractors lists the extractors added by the release that do not analyze a Linguist language.
+	Extractors []string `yaml:"extractors"`
+}
+
+// CodeQLSupport is the set of languages supported by a CodeQL version.
+type CodeQLSupport struct {
+	Version string
+	// Source describes where the version comes from, e.g. the GHES release it is bundled with.
+	Source string
+	// Languages maps the supported Linguist languages to their CodeQL extractor.
+	Languages map[string]string
+	// Extractors holds every supported extractor, including those that do not analyze a Linguist language.
+	Extractors map[string]struct{}
+}
+
+var codeqlSupport *CodeQLSupport
+
+// GetCodeQLSupport returns the CodeQL language support in use, which defaults to the latest CodeQL version
+// in the embedded catalog until ResolveCodeQLSupport is called.
+func GetCodeQLSupport() *CodeQLSupport {
+	if codeqlSupport == nil {
+		catalog, err := parseCodeQLCatalog(codeqlCatalogYAML)
+		if err != nil {
+			panic(fmt.Sprintf("invalid embedded CodeQL catalog: %v", err))
+		}
+		codeqlSupport, err = catalog.Support(catalog.Latest(), "latest in catalog")
+		if err != nil {
+			panic(fmt.Sprintf("invalid embedded CodeQL catalog: %v", err))
+		}
+	}
+	return codeqlSupport
+}
+
+// addCodeQLCatalogFlags registers the --codeql-version and --codeql-catalog flags on a command and its subcommands.
+func addCodeQLCatalogFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&codeql_version_flag, "codeql-version", "", "The CodeQL version whose supported languages are used (defaults to the version bundled with the GitHub Enterprise Server, or the latest)")
+	cmd.PersistentFlags().StringVar(&codeql_catalog_flag, "codeql-catalog", "", "A YAML file of CodeQL releases and GitHub Enterprise Server versions that extends the embedded CodeQL catalog")
+}
+
+func parseCodeQLCatalog(data []byte) (*CodeQLCatalog, error) {
+	var catalog CodeQLCatalog
+	if err := yaml.Unmarshal(data, &catalog); err != nil {
+		return nil, err
+	}
+	for _, release := range catalog.Releases {
+		if _, err := parseCodeQLVersion(release.Version); err != nil {
+			return nil, err
+		}
+	}
+	for ghes, version := range catalog.GHES {
+		if _, err := parseCodeQLVersion(version); err != nil {
+			return nil, fmt.Errorf("GHES %s: %v", ghes, err)
+		}
+	}
+	return &catalog, nil
+}
+
+// LoadCodeQLCatalog returns the embedded CodeQL catalog, extended with the releases and GHES versions of an
+// override file if one is given. Entries of the override file replace embedded entries for the same version.
+func LoadCodeQLCatalog(overrideFile string) (*CodeQLCatalog, error) {
+	catalog, err := parseCodeQLCatalog(codeqlCatalogYAML)
+	if err != nil {
+		return nil, fmt.Errorf("invalid embedded CodeQL catalog: %v", err)
+	}
+	if overrideFile == "" {
+		return catalog, nil
+	}
+
+	data, err := os.ReadFile(overrideFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CodeQL catalog: %v", err)
+	}
+	override, err := parseCodeQLCatalog(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CodeQL catalog '%s': %v", overrideFile, err)
+	}
+
+	for _, release := range override.Releases {
+		replaced := false
+		for i := range catalog.Releases {
+			if compareCodeQLVersions(catalog.Releases[i].Version, release.Version) == 0 {
+				catalog.Releases[i] = release
+				replaced = true
+			}
+		}
+		if !replaced {
+			catalog.Releases = append(catalog.Releases, release)
+		}
+	}
+	if catalog.GHES == nil {
+		catalog.GHES = make(map[string]string)
+	}
+	for ghes, version := range override.GHES {
+		catalog.GHES[ghes] = version
+	}
+	return catalog, nil
+}
+
+// Latest returns the most recent CodeQL version in the catalog.
+func (c *CodeQLCatalog) Latest() string {
+	latest := ""
+	for _, release := range c.Releases {
+		if latest == "" || compareCodeQLVersions(release.Version, latest) > 0 {
+			latest = release.Version
+		}
+	}
+	return latest
+}
+
+// Support returns the languages supported by a CodeQL version: those of every release up to and including it.
+func (c *CodeQLCatalog) Support(version, source string) (*CodeQLSupport, error) {
+	if _, err := parseCodeQLVersion(version); err != nil {
+		return nil, err
+	}
+	releases := make([]CodeQLRelease, len(c.Releases))
+	copy(releases, c.Releases)
+	sort.SliceStable(releases, func(i, j int) bool { return compareCodeQLVersions(releases[i].Version, releases[j].Version) < 0 })
+
+	support := &CodeQLSupport{
+		Version:    version,
+		Source:     source,
+		Languages:  make(map[string]string),
+		Extractors: make(map[string]struct{}),
+	}
+	for _, release := range releases {
+		if compareCodeQLVersions(release.Version, version) > 0 {
+			break
+		}
+		for lang, extractor := range release.Languages {
+			support.Languages[lang] = extractor
+			support.Extractors[extractor] = struct{}{}
+		}
+		for _, extractor := range release.Extractors {
+			support.Extractors[extractor] = struct{}{}
+		}
+	}
+	if len(support.Languages) == 0 {
+		return nil, fmt.Errorf("no CodeQL languages are known for CodeQL version %s", version)
+	}
+	return support, nil
+}
+
+// HasExtractor reports whether an extractor is supported.
+func (s *CodeQLSupport) HasExtractor(extractor string) bool {
+	_, ok := s.Extractors[extractor]
+	return ok
+}
+
+// String describes the CodeQL version in use for the run header.
+func (s *CodeQLSupport) String() string {
+	return fmt.Sprintf("CodeQL languages: CodeQL %s (%s)", s.Version, s.Source)
+}
+
+// FetchGHESVersion returns the installed version of a GitHub Enterprise Server (e.g., 3.12.4), or an empty
+// string for hosts that do not report one, such as github.com.
+func FetchGHESVersion(hostname string) (string, error) {
+	client, err := CreateRESTClient(hostname)
+	if err != nil {
+		return "", err
+	}
+	var meta struct {
+		InstalledVersion string `json:"installed_version"`
+	}
+	if err := client.Get("meta", &meta); err != nil {
+		return "", err
+	}
+	return meta.InstalledVersion, nil
+}
+
+// ResolveCodeQLSupport selects the CodeQL languages to use: those of --codeql-version if set, otherwise those of the
+// CodeQL version bundled with the GitHub Enterprise Server when detect is set, otherwise the latest in the catalog.
+func ResolveCodeQLSupport(version, catalogFile, hostname string, detect bool) (*CodeQLSupport, error) {
+	catalog, err := LoadCodeQLCatalog(catalogFile)
+	if err != nil {
+		return nil, err
+	}
+
+	source := "latest in catalog"
+	switch {
+	case version != "":
+		source = "--codeql-version"
+	case detect && hostname != "github.com":
+		installed, err := FetchGHESVersion(hostname)
+		if err != nil {
+			pterm.Warning.Printf("Failed to detect the GitHub Enterprise Server version, using the latest CodeQL languages: %v\n", err)
+			break
+		}
+		if installed == "" {
+			break
+		}
+		// The bundled CodeQL version only changes with feature releases, e.g. 3.12 for 3.12.4.
+		parts := strings.Split(installed, ".")
+		if len(parts) > 2 {
+			parts = parts[:2]
+		}
+		feature := strings.Join(parts, ".")
+		if bundled, ok := catalog.GHES[feature]; ok {
+			version = bundled
+			source = fmt.Sprintf("bundled with GHES %s", feature)
+		} else {
+			pterm.Warning.Printf("GHES %s is not in the CodeQL catalog, using the latest CodeQL languages. Use --codeql-version or --codeql-catalog to set them\n", installed)
+		}
+	}
+	if version == "" {
+		version = catalog.Latest()
+	}
+
+	support, err := catalog.Support(version, source)
+	if err != nil {
+		return nil, err
+	}
+	codeqlSupport = support
+	return support, nil
+}
+
+// parseCodeQLVersion parses a CodeQL version such as 2.16.1 (an optional leading "v" is accepted).
+func parseCodeQLVersion(version string) ([]int, error) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".")
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid CodeQL version '%s'", version)
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
+
+// compareCodeQLVersions compares two valid CodeQL versions, returning -1, 0 or 1.
+func compareCodeQLVersions(a, b string) int {
+	va, _ := parseCodeQLVersion(a)
+	vb, _ := parseCodeQLVersion(b)
+	for i := 0; i < len(va) || i < len(vb); i++ {
+		var x, y int
+		if i < len(va) {
+			x = va[i]
+		}
+		if i < len(vb) {
+			y = vb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// resolveCodeQLSupportForCommand resolves the CodeQL languages before a command runs. The GHES version is only
+// detected, and the CodeQL version only printed, when the command uses CodeQL languages.
+func resolveCodeQLSupportForCommand(cmd *cobra.Command, args []string) error {
+	usesCodeQL := resolvePresetName() == CODEQL_PRESET || strings.HasPrefix(cmd.Name(), "codeql")
+	if !usesCodeQL && codeql_version_flag == "" && codeql_catalog_flag == "" {
+		return nil
+	}
+	support, err := ResolveCodeQLSupport(codeql_version_flag, codeql_catalog_flag, github_enterprise_server_url_flag, usesCodeQL)
+	if err != nil {
+		return err
+	}
+	if usesCodeQL {
+		pterm.Info.Println(support.String())
+	}
+	return nil
+}
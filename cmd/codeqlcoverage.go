@@ -0,0 +1,338 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var recent_days_flag int
+
+func init() {
+	addThresholdFlags(codeqlCoverageCmd)
+	codeqlCoverageCmd.Flags().IntVar(&recent_days_flag, "recent-days", 30, "The number of days within which a CodeQL analysis counts as recent")
+}
+
+var codeqlCoverageCmd = &cobra.Command{
+	Use:   "codeql-coverage",
+	Short: "Analyze which repos are configured and recently analyzed by CodeQL code scanning for each language they contain",
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		return runCodeQLCoverage(cmd, args)
+	},
+}
+
+// codeqlRepoStatus is the code scanning status of a repository for the CodeQL languages it contains.
+type codeqlRepoStatus struct {
+	Repository
+	// Languages are the CodeQL-supported Linguist languages of the repository that pass the filters.
+	Languages []string
+	// Setup is "default", "advanced" or "none".
+	Setup string
+	// Configured holds the extractors set up for the repository, by default setup or by an advanced setup analysis.
+	Configured map[string]struct{}
+	// LastAnalyzed holds the time of the most recent CodeQL analysis per extractor.
+	LastAnalyzed map[string]time.Time
+}
+
+// FetchCodeQLStatus fetches the default setup configuration and the most recent CodeQL analyses of a repository.
+func FetchCodeQLStatus(client *api.RESTClient, repo Repository) (*codeqlRepoStatus, error) {
+	status := &codeqlRepoStatus{
+		Repository:   repo,
+		Setup:        "none",
+		Configured:   make(map[string]struct{}),
+		LastAnalyzed: make(map[string]time.Time),
+	}
+
+	var defaultSetup struct {
+		State     string   `json:"state"`
+		Languages []string `json:"languages"`
+	}
+	found, err := fetchIfFound(client, fmt.Sprintf("repos/%s/%s/code-scanning/default-setup", repo.Org, repo.Name), &defaultSetup)
+	if err != nil {
+		return nil, err
+	}
+	if found && defaultSetup.State == "configured" {
+		status.Setup = "default"
+		for _, lang := range defaultSetup.Languages {
+			status.Configured[NormalizeCodeQLLanguage(lang)] = struct{}{}
+		}
+	}
+
+	// Analyses are returned most recent first, so the first page holds the latest analysis of each language.
+	var analyses []struct {
+		Category  string `json:"category"`
+		CreatedAt string `json:"created_at"`
+	}
+	found, err = fetchIfFound(client, fmt.Sprintf("repos/%s/%s/code-scanning/analyses?tool_name=CodeQL&per_page=100", repo.Org, repo.Name), &analyses)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return status, nil
+	}
+	for _, analysis := range analyses {
+		// CodeQL analyses are categorized as /language:<language>.
+		lang, ok := strings.CutPrefix(analysis.Category, "/language:")
+		if !ok {
+			continue
+		}
+		extractor := NormalizeCodeQLLanguage(lang)
+		created, err := time.Parse(time.RFC3339, analysis.CreatedAt)
+		if err != nil {
+			continue
+		}
+		if created.After(status.LastAnalyzed[extractor]) {
+			status.LastAnalyzed[extractor] = created
+		}
+		// Analyses without default setup come from an advanced setup workflow.
+		if status.Setup != "default" {
+			status.Setup = "advanced"
+			status.Configured[extractor] = struct{}{}
+		}
+	}
+	return status, nil
+}
+
+// isCodeScanningUnavailable reports whether an error is the 403 returned for repositories that cannot use code
+// scanning because GitHub Advanced Security (or GitHub Code Security) is not enabled.
+func isCodeScanningUnavailable(err error) bool {
+	var httpErr *api.HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusForbidden &&
+		strings.Contains(strings.ToLower(httpErr.Message), "security must be enabled")
+}
+
+// IsConfigured reports whether a Linguist language of the repository is set up for CodeQL.
+func (s *codeqlRepoStatus) IsConfigured(lang string) bool {
+	extractor, _ := CodeQLExtractor(lang)
+	_, ok := s.Configured[extractor]
+	return ok
+}
+
+// IsRecentlyAnalyzed reports whether a Linguist language of the repository was analyzed by CodeQL since the given time.
+func (s *codeqlRepoStatus) IsRecentlyAnalyzed(lang string, since time.Time) bool {
+	extractor, _ := CodeQLExtractor(lang)
+	return s.LastAnalyzed[extractor].After(since)
+}
+
+func runCodeQLCoverage(cmd *cobra.Command, args []string) error {
+	org := org_flag
+	enterprise := enterprise_flag
+	repoLimit := repo_limit_flag
+	orgLimit := org_limit_flag
+	hostname := github_enterprise_server_url_flag
+
+	if err := ValidateFlags(org, enterprise); err != nil {
+		return err
+	}
+
+	// Coverage is always restricted to CodeQL-supported languages, optionally narrowed with --language.
+	pipeline, err := CodeQLLanguagePipelineFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	if recent_days_flag <= 0 {
+		return fmt.Errorf("--recent-days must be greater than 0")
+	}
+
+	order, err := ParseRepositoryOrder(order_by_flag, order_flag)
+	if err != nil {
+		return err
+	}
+
+	sizeFilter, err := NewSizeFilter(min_size_flag, max_size_flag)
+	if err != nil {
+		return err
+	}
+
+	orgFilter, err := ParseOrgFilter(org_include_flag, org_exclude_flag, orgs_file_flag)
+	if err != nil {
+		return err
+	}
+
+	// Describe the language filters in the order they are applied.
+	languageFilter := pipeline.String()
+	if !sizeFilter.IsEmpty() {
+		languageFilter += ", " + sizeFilter.String()
+	}
+	if !order.IsEmpty() {
+		languageFilter += ", " + order.String()
+	}
+	orgs, err := ResolveOrganizations(enterprise, org, orgLimit, repoLimit, languageFilter, orgFilter, hostname)
+	if err != nil {
+		return err
+	}
+
+	// Code scanning configurations and analyses are only available through the REST API.
+	client, err := CreateRESTClient(hostname)
+	if err != nil {
+		pterm.Error.Println("Failed to create REST client:", err)
+		return err
+	}
+
+	var statuses []*codeqlRepoStatus
+	// Repositories without GitHub Advanced Security are reported separately, as neither covered nor gaps.
+	var unavailable []*codeqlRepoStatus
+	var totalRepos, archivedRepos int
+
+	// Iterate over each organization to fetch repositories and their code scanning status.
+	for orgIndex, org := range orgs {
+		// Count and fetch the repositories of the organization with their languages.
+		repos, err := IndexOrganizationRepositories(org, orgIndex, len(orgs), repoLimit, order, hostname)
+		if err != nil {
+			return err
+		}
+
+		// Drop repositories outside the --min-size and --max-size range.
+		repos = sizeFilter.Apply(repos)
+
+		// Increment the total repository count.
+		totalRepos += len(repos)
+
+		progressBar, _ := pterm.DefaultProgressbar.WithTotal(len(repos)).WithTitle("Fetching code scanning status").Start()
+		for _, repo := range repos {
+			progressBar.Increment()
+
+			// Code scanning cannot be set up on archived repositories, so they are not coverage gaps.
+			if repo.IsArchived {
+				archivedRepos++
+				continue
+			}
+
+			// Keep the CodeQL-supported languages that meet the threshold and pass the language filters.
+			var repoLanguages []string
+			for lang := range pipeline.RepositoryLanguages(repo) {
+				repoLanguages = append(repoLanguages, lang)
+			}
+			if len(repoLanguages) == 0 {
+				continue
+			}
+			sort.Strings(repoLanguages)
+
+			status, err := FetchCodeQLStatus(client, repo)
+			if isCodeScanningUnavailable(err) {
+				unavailable = append(unavailable, &codeqlRepoStatus{Repository: repo, Languages: repoLanguages})
+				continue
+			}
+			if err != nil {
+				// Any other error would turn the repository into a false coverage gap, so the run stops instead.
+				progressBar.Stop()
+				pterm.Error.Printf("Failed to fetch the code scanning status of repository '%s/%s': %v\n", repo.Org, repo.Name, err)
+				return err
+			}
+			status.Languages = repoLanguages
+			statuses = append(statuses, status)
+		}
+		progressBar.Stop()
+	}
+
+	// Print the total number of repositories analyzed.
+	pterm.Println() // Add a new line
+	pterm.Info.Println(fmt.Sprintf("Total number of repositories analyzed: %d", totalRepos))
+	PrintSizeFilterSummary(sizeFilter)
+	if archivedRepos > 0 {
+		pterm.Info.Println(fmt.Sprintf("Archived repositories skipped: %d", archivedRepos))
+	}
+	pterm.Info.Println(fmt.Sprintf("Repositories with at least one CodeQL-supported language: %d", len(statuses)+len(unavailable)))
+	if len(unavailable) > 0 {
+		pterm.Info.Println(fmt.Sprintf("Repositories where code scanning is unavailable (not included in coverage): %d", len(unavailable)))
+	}
+	// Print the language threshold so that results remain comparable across runs.
+	if thresholdInfo := GetThresholdInfo(min_bytes_flag, min_share_flag); thresholdInfo != "" {
+		pterm.Info.Println(thresholdInfo)
+	}
+	pipeline.WarnUnknownTypes()
+	pterm.Println() // Add a new line
+
+	since := time.Now().AddDate(0, 0, -recent_days_flag)
+
+	// ── Section 1: Coverage per CodeQL Language ─────────────────────
+	containing := make(map[string]int)
+	configured := make(map[string]int)
+	analyzed := make(map[string]int)
+	setups := make(map[string]int)
+	for _, status := range statuses {
+		setups[status.Setup]++
+		for _, lang := range status.Languages {
+			containing[lang]++
+			if status.IsConfigured(lang) {
+				configured[lang]++
+			}
+			if status.IsRecentlyAnalyzed(lang, since) {
+				analyzed[lang]++
+			}
+		}
+	}
+
+	pterm.DefaultSection.Println("CodeQL Coverage by Language")
+	rows := [][]string{{"Language", "Extractor", "Repos", "Configured", fmt.Sprintf("Analyzed (Last %d Days)", recent_days_flag), "Coverage", "Gaps"}}
+	for _, lang := range topLanguageNames(containing, "", 0) {
+		extractor, _ := CodeQLExtractor(lang)
+		rows = append(rows, []string{
+			lang,
+			extractor,
+			fmt.Sprintf("%d", containing[lang]),
+			fmt.Sprintf("%d", configured[lang]),
+			fmt.Sprintf("%d", analyzed[lang]),
+			FormatPercentage(analyzed[lang], containing[lang], false),
+			fmt.Sprintf("%d", containing[lang]-analyzed[lang]),
+		})
+	}
+	pterm.DefaultTable.WithHasHeader(true).WithData(rows).Render()
+	pterm.Println()
+
+	pterm.Info.Println(fmt.Sprintf("Setup: %d default, %d advanced, %d none", setups["default"], setups["advanced"], setups["none"]))
+	pterm.Println()
+
+	// ── Section 2: Coverage Gaps ────────────────────────────────────
+	// Repositories with a CodeQL language that is not configured, or configured but not analyzed recently.
+	gapRows := [][]string{{"Organization", "Repository", "Setup", "Not Configured", "Not Recently Analyzed", "URL"}}
+	for _, status := range statuses {
+		var notConfigured, notAnalyzed []string
+		for _, lang := range status.Languages {
+			switch {
+			case !status.IsConfigured(lang):
+				notConfigured = append(notConfigured, lang)
+			case !status.IsRecentlyAnalyzed(lang, since):
+				notAnalyzed = append(notAnalyzed, lang)
+			}
+		}
+		if len(notConfigured) == 0 && len(notAnalyzed) == 0 {
+			continue
+		}
+		gapRows = append(gapRows, []string{
+			status.Org,
+			status.Name,
+			status.Setup,
+			strings.Join(notConfigured, ", "),
+			strings.Join(notAnalyzed, ", "),
+			status.URL,
+		})
+	}
+
+	pterm.DefaultSection.Println(fmt.Sprintf("Coverage Gaps (%d)", len(gapRows)-1))
+	if len(gapRows) > 1 {
+		pterm.DefaultTable.WithHasHeader(true).WithData(gapRows).Render()
+	}
+
+	// ── Section 3: Code Scanning Unavailable ────────────────────────
+	// Repositories where GitHub Advanced Security must be enabled before code scanning can be set up.
+	if len(unavailable) > 0 {
+		pterm.Println()
+		pterm.DefaultSection.Println(fmt.Sprintf("Code Scanning Unavailable (%d)", len(unavailable)))
+		unavailableRows := [][]string{{"Organization", "Repository", "CodeQL Languages", "URL"}}
+		for _, status := range unavailable {
+			unavailableRows = append(unavailableRows, []string{status.Org, status.Name, strings.Join(status.Languages, ", "), status.URL})
+		}
+		pterm.DefaultTable.WithHasHeader(true).WithData(unavailableRows).Render()
+	}
+
+	return nil
+}
@@ -0,0 +1,339 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var wave_size_flag int
+var plan_output_flag string
+
+func init() {
+	addThresholdFlags(codeqlPlanCmd)
+	codeqlPlanCmd.Flags().IntVar(&wave_size_flag, "wave-size", 50, "The number of repos per rollout wave")
+	codeqlPlanCmd.Flags().StringVar(&plan_output_flag, "output", "codeql-plan.json", "The JSON file to write the rollout waves to")
+}
+
+var codeqlPlanCmd = &cobra.Command{
+	Use:   "codeql-plan",
+	Short: "Plan a CodeQL default setup rollout in waves, classifying repos by CodeQL extractor",
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		return runCodeQLPlan(cmd, args)
+	},
+}
+
+// CodeQLPlan is the rollout plan written by the codeql-plan command.
+type CodeQLPlan struct {
+	GeneratedAt   string       `json:"generated_at"`
+	Hostname      string       `json:"hostname"`
+	Enterprise    string       `json:"enterprise,omitempty"`
+	Organizations []string     `json:"organizations"`
+	WaveSize      int          `json:"wave_size"`
+	Waves         []CodeQLWave `json:"waves"`
+}
+
+// CodeQLWave is a batch of repositories to enable default setup on together.
+type CodeQLWave struct {
+	Wave         int                    `json:"wave"`
+	Repositories []CodeQLPlanRepository `json:"repositories"`
+}
+
+// CodeQLPlanRepository is a repository of a rollout wave. DefaultSetup is the request body for the
+// "Update a code scanning default setup configuration" REST API endpoint.
+type CodeQLPlanRepository struct {
+	Repository   string             `json:"repository"`
+	URL          string             `json:"html_url"`
+	Compiled     bool               `json:"compiled"`
+	Notes        []string           `json:"notes,omitempty"`
+	DefaultSetup CodeQLDefaultSetup `json:"default_setup"`
+}
+
+// CodeQLDefaultSetup is a code scanning default setup configuration.
+type CodeQLDefaultSetup struct {
+	State      string   `json:"state"`
+	Languages  []string `json:"languages"`
+	QuerySuite string   `json:"query_suite"`
+}
+
+// HasWorkflows reports whether a repository contains GitHub Actions workflows, which CodeQL analyzes with the
+// actions extractor.
+func HasWorkflows(client *api.RESTClient, repo Repository) (bool, error) {
+	var entries []struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	}
+	found, err := fetchIfFound(client, fmt.Sprintf("repos/%s/%s/contents/.github/workflows", repo.Org, repo.Name), &entries)
+	if err != nil || !found {
+		return false, err
+	}
+	for _, entry := range entries {
+		if entry.Type == "file" && (strings.HasSuffix(entry.Name, ".yml") || strings.HasSuffix(entry.Name, ".yaml")) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// sortExtractors sorts extractors in CODEQL_EXTRACTOR_ORDER, followed by any other extractors by name.
+func sortExtractors(extractors []string) {
+	rank := make(map[string]int, len(CODEQL_EXTRACTOR_ORDER))
+	for i, extractor := range CODEQL_EXTRACTOR_ORDER {
+		rank[extractor] = i + 1
+	}
+	sort.SliceStable(extractors, func(i, j int) bool {
+		ri, rj := rank[extractors[i]], rank[extractors[j]]
+		if ri == 0 || rj == 0 {
+			if ri == rj {
+				return extractors[i] < extractors[j]
+			}
+			return rj == 0
+		}
+		return ri < rj
+	})
+}
+
+// planTier ranks how much effort a repository's rollout is likely to take: 0 for interpreted languages only,
+// 1 for compiled languages, and 2 for languages that need macOS runners.
+func planTier(extractors []string) int {
+	tier := 0
+	for _, extractor := range extractors {
+		if extractor == "swift" {
+			return 2
+		}
+		if _, ok := CODEQL_COMPILED_EXTRACTORS[extractor]; ok {
+			tier = 1
+		}
+	}
+	return tier
+}
+
+func runCodeQLPlan(cmd *cobra.Command, args []string) error {
+	org := org_flag
+	enterprise := enterprise_flag
+	repoLimit := repo_limit_flag
+	orgLimit := org_limit_flag
+	hostname := github_enterprise_server_url_flag
+
+	if err := ValidateFlags(org, enterprise); err != nil {
+		return err
+	}
+
+	// The plan is always restricted to CodeQL-supported languages, optionally narrowed with --language.
+	pipeline, err := CodeQLLanguagePipelineFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	if wave_size_flag <= 0 {
+		return fmt.Errorf("--wave-size must be greater than 0")
+	}
+
+	order, err := ParseRepositoryOrder(order_by_flag, order_flag)
+	if err != nil {
+		return err
+	}
+
+	sizeFilter, err := NewSizeFilter(min_size_flag, max_size_flag)
+	if err != nil {
+		return err
+	}
+
+	orgFilter, err := ParseOrgFilter(org_include_flag, org_exclude_flag, orgs_file_flag)
+	if err != nil {
+		return err
+	}
+
+	// Describe the language filters in the order they are applied.
+	languageFilter := pipeline.String()
+	if !sizeFilter.IsEmpty() {
+		languageFilter += ", " + sizeFilter.String()
+	}
+	if !order.IsEmpty() {
+		languageFilter += ", " + order.String()
+	}
+	orgs, err := ResolveOrganizations(enterprise, org, orgLimit, repoLimit, languageFilter, orgFilter, hostname)
+	if err != nil {
+		return err
+	}
+
+	// Workflows are detected through the REST contents API.
+	client, err := CreateRESTClient(hostname)
+	if err != nil {
+		pterm.Error.Println("Failed to create REST client:", err)
+		return err
+	}
+
+	var planned []CodeQLPlanRepository
+	var tiers []int
+	var totalRepos, archivedRepos int
+	extractorCounts := make(map[string]int)
+
+	// Iterate over each organization to fetch repositories and classify them by extractor.
+	for orgIndex, org := range orgs {
+		// Count and fetch the repositories of the organization with their languages.
+		repos, err := IndexOrganizationRepositories(org, orgIndex, len(orgs), repoLimit, order, hostname)
+		if err != nil {
+			return err
+		}
+
+		// Drop repositories outside the --min-size and --max-size range.
+		repos = sizeFilter.Apply(repos)
+
+		// Increment the total repository count.
+		totalRepos += len(repos)
+
+		progressBar, _ := pterm.DefaultProgressbar.WithTotal(len(repos)).WithTitle("Classifying repositories by CodeQL extractor").Start()
+		for _, repo := range repos {
+			progressBar.Increment()
+
+			// Default setup cannot be enabled on archived repositories.
+			if repo.IsArchived {
+				archivedRepos++
+				continue
+			}
+
+			extractorSet := make(map[string]struct{})
+			for lang := range pipeline.RepositoryLanguages(repo) {
+				if extractor, ok := CodeQLExtractor(lang); ok {
+					extractorSet[extractor] = struct{}{}
+				}
+			}
+			// Workflows are only planned when the CodeQL version in use analyzes them, and no --language filter
+			// narrows the plan to other languages.
+			if len(pipeline.Languages) == 0 && GetCodeQLSupport().HasExtractor(CODEQL_ACTIONS_EXTRACTOR) {
+				hasWorkflows, err := HasWorkflows(client, repo)
+				if err != nil {
+					pterm.Warning.Printf("Failed to check workflows for repository '%s/%s': %v\n", repo.Org, repo.Name, err)
+				}
+				if hasWorkflows {
+					extractorSet[CODEQL_ACTIONS_EXTRACTOR] = struct{}{}
+				}
+			}
+			if len(extractorSet) == 0 {
+				continue
+			}
+
+			extractors := make([]string, 0, len(extractorSet))
+			for extractor := range extractorSet {
+				extractors = append(extractors, extractor)
+				extractorCounts[extractor]++
+			}
+			sortExtractors(extractors)
+
+			entry := CodeQLPlanRepository{
+				Repository:   fmt.Sprintf("%s/%s", repo.Org, repo.Name),
+				URL:          repo.URL,
+				DefaultSetup: CodeQLDefaultSetup{State: "configured", Languages: extractors, QuerySuite: "default"},
+			}
+			for _, extractor := range extractors {
+				if note, ok := CODEQL_COMPILED_EXTRACTORS[extractor]; ok {
+					entry.Compiled = true
+					entry.Notes = append(entry.Notes, fmt.Sprintf("%s: %s", extractor, note))
+				}
+			}
+			planned = append(planned, entry)
+			tiers = append(tiers, planTier(extractors))
+		}
+		progressBar.Stop()
+	}
+
+	// Roll out the repositories that are least likely to need attention first, keeping the fetch order within a tier.
+	indexes := make([]int, len(planned))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool { return tiers[indexes[i]] < tiers[indexes[j]] })
+
+	plan := CodeQLPlan{
+		GeneratedAt:   time.Now().UTC().Format(GITHUB_TIMESTAMP_LAYOUT),
+		Hostname:      hostname,
+		Enterprise:    enterprise,
+		Organizations: orgs,
+		WaveSize:      wave_size_flag,
+	}
+	for start := 0; start < len(indexes); start += wave_size_flag {
+		end := start + wave_size_flag
+		if end > len(indexes) {
+			end = len(indexes)
+		}
+		wave := CodeQLWave{Wave: len(plan.Waves) + 1}
+		for _, i := range indexes[start:end] {
+			wave.Repositories = append(wave.Repositories, planned[i])
+		}
+		plan.Waves = append(plan.Waves, wave)
+	}
+
+	// Print the total number of repositories analyzed.
+	pterm.Println() // Add a new line
+	pterm.Info.Println(fmt.Sprintf("Total number of repositories analyzed: %d", totalRepos))
+	PrintSizeFilterSummary(sizeFilter)
+	if archivedRepos > 0 {
+		pterm.Info.Println(fmt.Sprintf("Archived repositories skipped: %d", archivedRepos))
+	}
+	pterm.Info.Println(fmt.Sprintf("Repositories with at least one CodeQL extractor: %d", len(planned)))
+	// Print the language threshold so that results remain comparable across runs.
+	if thresholdInfo := GetThresholdInfo(min_bytes_flag, min_share_flag); thresholdInfo != "" {
+		pterm.Info.Println(thresholdInfo)
+	}
+	pipeline.WarnUnknownTypes()
+	pterm.Println() // Add a new line
+
+	// ── Section 1: Repositories per Extractor ───────────────────────
+	pterm.DefaultSection.Println("Repositories by CodeQL Extractor")
+	rows := [][]string{{"Extractor", "Repos", "Percentage", "Note"}}
+	for _, extractor := range CODEQL_EXTRACTOR_ORDER {
+		if extractorCounts[extractor] == 0 {
+			continue
+		}
+		rows = append(rows, []string{
+			extractor,
+			fmt.Sprintf("%d", extractorCounts[extractor]),
+			FormatPercentage(extractorCounts[extractor], len(planned), false),
+			CODEQL_COMPILED_EXTRACTORS[extractor],
+		})
+	}
+	pterm.DefaultTable.WithHasHeader(true).WithData(rows).Render()
+	pterm.Println()
+
+	// ── Section 2: Rollout Waves ────────────────────────────────────
+	pterm.DefaultSection.Println(fmt.Sprintf("Rollout Waves (%d)", len(plan.Waves)))
+	waveRows := [][]string{{"Wave", "Repos", "Compiled", "macOS Runners"}}
+	for _, wave := range plan.Waves {
+		var compiled, macOS int
+		for _, repo := range wave.Repositories {
+			if repo.Compiled {
+				compiled++
+			}
+			if planTier(repo.DefaultSetup.Languages) == 2 {
+				macOS++
+			}
+		}
+		waveRows = append(waveRows, []string{
+			fmt.Sprintf("%d", wave.Wave),
+			fmt.Sprintf("%d", len(wave.Repositories)),
+			fmt.Sprintf("%d", compiled),
+			fmt.Sprintf("%d", macOS),
+		})
+	}
+	pterm.DefaultTable.WithHasHeader(true).WithData(waveRows).Render()
+	pterm.Println()
+
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode rollout plan: %v", err)
+	}
+	if err := os.WriteFile(plan_output_flag, data, 0644); err != nil {
+		return fmt.Errorf("failed to write rollout plan: %v", err)
+	}
+	pterm.Info.Println(fmt.Sprintf("Saved %d repositories in %d waves to %s", len(planned), len(plan.Waves), plan_output_flag))
+
+	return nil
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -12,8 +13,12 @@ import (
 	"github.com/cli/go-gh/v2"
 	"github.com/cli/go-gh/v2/pkg/api"
 	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
 )
 
+// NO_PRIMARY_LANGUAGE is the language reported for repositories without a primary language in --primary mode.
+const NO_PRIMARY_LANGUAGE = "None"
+
 // CreateRESTClient creates a REST client with the specified hostname.
 func CreateRESTClient(hostname string) (*api.RESTClient, error) {
 	opts := api.ClientOptions{
@@ -22,24 +27,84 @@ func CreateRESTClient(hostname string) (*api.RESTClient, error) {
 	return api.NewRESTClient(opts)
 }
 
+// fetchIfFound fetches a REST endpoint. It returns false without an error when the endpoint returns 404,
+// e.g. for repositories without a code scanning configuration or a given file.
+func fetchIfFound(client *api.RESTClient, path string, response interface{}) (bool, error) {
+	err := getWithRetry(client, path, response)
+	var httpErr *api.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == 404 {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// REST_RETRIES is the number of times a REST request is retried after a rate limit or server error.
+const REST_RETRIES = 3
+
+// getWithRetry fetches a REST endpoint, waiting and retrying when it is rate limited (including secondary
+// rate limits) or fails with a server error. Other errors are returned as is.
+func getWithRetry(client *api.RESTClient, path string, response interface{}) error {
+	for attempt := 0; ; attempt++ {
+		err := client.Get(path, response)
+		wait, retry := retryDelay(err, attempt)
+		if !retry || attempt >= REST_RETRIES {
+			return err
+		}
+		pterm.Warning.Printf("Request for '%s' failed (%v). Retrying in %v...\n", path, err, wait)
+		time.Sleep(wait)
+	}
+}
+
+// retryDelay returns how long to wait before retrying a failed REST request, and whether to retry it at all.
+func retryDelay(err error, attempt int) (time.Duration, bool) {
+	var httpErr *api.HTTPError
+	if !errors.As(err, &httpErr) {
+		return 0, false
+	}
+	rateLimited := httpErr.StatusCode == http.StatusTooManyRequests ||
+		(httpErr.StatusCode == http.StatusForbidden && (httpErr.Headers.Get("Retry-After") != "" ||
+			httpErr.Headers.Get("X-RateLimit-Remaining") == "0" ||
+			strings.Contains(strings.ToLower(httpErr.Message), "rate limit")))
+	switch {
+	case rateLimited:
+		if seconds, err := strconv.Atoi(httpErr.Headers.Get("Retry-After")); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if reset, err := strconv.ParseInt(httpErr.Headers.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			if wait := time.Until(time.Unix(reset, 0)); wait > 0 {
+				return wait, true
+			}
+		}
+		// Secondary rate limits without a Retry-After header ask to wait at least a minute.
+		return time.Minute, true
+	case httpErr.StatusCode >= 500:
+		return time.Duration(1<<attempt) * time.Second, true
+	}
+	return 0, false
+}
+
 func Red(s string) string {
 	return "\x1b[31m" + s + "\x1b[m"
 }
 
 // FetchOrganizations fetches organizations for a given enterprise using the GitHub GraphQL API.
-func FetchOrganizations(enterprise string, orgLimit int, hostname string) ([]string, error) {
+// Organizations that do not match the filter are skipped before the limit is applied, and are
+// returned separately so that they can be reported.
+func FetchOrganizations(enterprise string, orgLimit int, hostname string, filter OrgFilter) ([]string, []string, error) {
 	if enterprise == "" {
-		return nil, fmt.Errorf("--enterprise flag is required")
+		return nil, nil, fmt.Errorf("--enterprise flag is required")
 	}
 
 	const maxPerPage = 100
 	var orgs []string
+	var skipped []string
 	var cursor *string
 	fetched := 0
 
 	for {
 		remaining := orgLimit - fetched
-		if remaining > maxPerPage {
+		// Skipped organizations do not count toward the limit, so always request full pages when filtering.
+		if remaining > maxPerPage || !filter.IsEmpty() {
 			remaining = maxPerPage
 		}
 
@@ -62,7 +127,7 @@ func FetchOrganizations(enterprise string, orgLimit int, hostname string) ([]str
 			pterm.Error.Printf("Failed to fetch organizations for enterprise '%s': %v\n", enterprise, err)
 			pterm.Error.Printf("GraphQL query: %s\n", query)
 			pterm.Error.Printf("gh CLI stderr: %s\n", stderr.String())
-			return nil, err
+			return nil, nil, err
 		}
 
 		var result struct {
@@ -83,14 +148,18 @@ func FetchOrganizations(enterprise string, orgLimit int, hostname string) ([]str
 
 		if err := json.Unmarshal(response.Bytes(), &result); err != nil {
 			pterm.Error.Printf("Failed to parse organizations data for enterprise '%s': %v\n", enterprise, err)
-			return nil, err
+			return nil, nil, err
 		}
 
 		for _, org := range result.Data.Enterprise.Organizations.Nodes {
+			if !filter.Matches(org.Login) {
+				skipped = append(skipped, org.Login)
+				continue
+			}
 			orgs = append(orgs, org.Login)
 			fetched++
 			if fetched >= orgLimit {
-				return orgs, nil
+				return orgs, skipped, nil
 			}
 		}
 
@@ -100,6 +169,35 @@ func FetchOrganizations(enterprise string, orgLimit int, hostname string) ([]str
 		cursor = &result.Data.Enterprise.Organizations.PageInfo.EndCursor
 	}
 
+	return orgs, skipped, nil
+}
+
+// ResolveOrganizations prints the run header and returns the organizations to analyze: either the
+// filtered organizations of an enterprise, or the single organization provided.
+func ResolveOrganizations(enterprise, org string, orgLimit, repoLimit int, languageFilter string, orgFilter OrgFilter, hostname string) ([]string, error) {
+	if enterprise == "" {
+		if !orgFilter.IsEmpty() {
+			return nil, fmt.Errorf("--org-include, --org-exclude and --orgs-file require the --enterprise flag")
+		}
+		// Handle the case where only a single organization is provided.
+		PrintInfoWithFormat("Repository limit: %d, %s", repoLimit, languageFilter)
+		return []string{org}, nil
+	}
+
+	// Print organization and repository limits along with the language and organization filters.
+	PrintInfoWithFormat("Organization limit: %d, Repository limit: %d, %s", orgLimit, repoLimit, languageFilter)
+	if orgFilterInfo := orgFilter.String(); orgFilterInfo != "" {
+		PrintInfo(orgFilterInfo)
+	}
+	spinnerEnterprise, _ := StartIndexingEnterpriseSpinner(enterprise)
+	orgs, skipped, err := FetchOrganizations(enterprise, orgLimit, hostname, orgFilter)
+	if err != nil {
+		spinnerEnterprise.Fail("Failed to index organizations for enterprise")
+		return nil, err
+	}
+	spinnerEnterprise.Success(fmt.Sprintf("Successfully indexed enterprise: %s", enterprise))
+	PrintTotalOrganizations(len(orgs))
+	PrintSkippedOrganizations(skipped)
 	return orgs, nil
 }
 
@@ -110,21 +208,20 @@ func formatCursor(cursor *string) string {
 	return `"` + *cursor + `"`
 }
 
-// FetchRepositories fetches repositories for a given organization and limit.
-func FetchRepositories(client *api.RESTClient, org string, limit int) ([]struct {
-	Name      string `json:"name"`
-	CreatedAt string `json:"created_at"`
-}, error) {
+// FetchRepositories fetches repositories for a given organization and limit. Language fields are
+// not populated by the REST API and must be fetched separately with FetchLanguages.
+func FetchRepositories(client *api.RESTClient, org string, limit int, order RepositoryOrder) ([]Repository, error) {
 	if org == "" {
 		return nil, fmt.Errorf("no organization identified, please ensure you have access to the organization or enterprise provided")
 	}
 
-	var allRepos []struct {
-		Name      string `json:"name"`
-		CreatedAt string `json:"created_at"`
-	}
+	var allRepos []Repository
 
-	requestPath := fmt.Sprintf("orgs/%s/repos?per_page=100", org)
+	orderParameters, err := order.RESTParameters()
+	if err != nil {
+		return nil, err
+	}
+	requestPath := fmt.Sprintf("orgs/%s/repos?per_page=100%s", org, orderParameters)
 	fetched := 0
 
 	for {
@@ -145,16 +242,18 @@ func FetchRepositories(client *api.RESTClient, org string, limit int) ([]struct
 			continue
 		}
 
-		var repos []struct {
-			Name      string `json:"name"`
-			CreatedAt string `json:"created_at"`
-		}
+		var repos []Repository
 		if err := json.NewDecoder(response.Body).Decode(&repos); err != nil {
 			pterm.Error.Println("Failed to parse repositories data:", err)
 			return nil, err
 		}
 		response.Body.Close()
 
+		// The REST API reports the owner as an object, so record the organization directly.
+		for i := range repos {
+			repos[i].Org = org
+		}
+
 		allRepos = append(allRepos, repos...)
 		fetched += len(repos)
 		if fetched >= limit || len(repos) == 0 {
@@ -187,6 +286,55 @@ func findNextPage(linkHeader string) string {
 	return ""
 }
 
+// fetchRESTPages fetches every page of a paginated REST endpoint and decodes each page into a slice of T.
+func fetchRESTPages[T any](client *api.RESTClient, requestPath string) ([]T, error) {
+	var all []T
+	for {
+		page, nextPageURL, err := fetchRESTPage[T](client, requestPath)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+
+		if nextPageURL == "" || len(page) == 0 {
+			break
+		}
+		requestPath = nextPageURL
+	}
+	return all, nil
+}
+
+// fetchRESTPage fetches a single page of a REST endpoint, waiting for the rate limit to reset if needed.
+// It returns the decoded page and the URL of the next page, if any.
+func fetchRESTPage[T any](client *api.RESTClient, requestPath string) ([]T, string, error) {
+	for {
+		response, err := client.Request(http.MethodGet, requestPath, nil)
+		if err != nil {
+			return nil, "", err
+		}
+
+		// Check rate limit headers
+		remaining := response.Header.Get("X-RateLimit-Remaining")
+		reset := response.Header.Get("X-RateLimit-Reset")
+		if remaining == "0" {
+			response.Body.Close()
+			resetTime, _ := strconv.Atoi(reset)
+			waitDuration := time.Until(time.Unix(int64(resetTime), 0))
+			pterm.Warning.Printf("Rate limit exceeded. Waiting for %v...\n", waitDuration)
+			time.Sleep(waitDuration)
+			continue
+		}
+
+		var page []T
+		err = json.NewDecoder(response.Body).Decode(&page)
+		response.Body.Close()
+		if err != nil {
+			return nil, "", err
+		}
+		return page, findNextPage(response.Header.Get("Link")), nil
+	}
+}
+
 // ShowProgressBar displays a progress bar.
 func ShowProgressBar(total int, title string) {
 	progressBar, _ := pterm.DefaultProgressbar.WithTotal(total).WithTitle(title).Start()
@@ -194,46 +342,97 @@ func ShowProgressBar(total int, title string) {
 	progressBar.Stop()
 }
 
-// GetCodeQLLanguages returns the set of CodeQL-supported languages.
+// GetCodeQLLanguages returns the set of languages supported by the CodeQL version in use.
 func GetCodeQLLanguages() map[string]bool {
-	return map[string]bool{
-		"C":          true,
-		"C++":        true,
-		"C#":         true,
-		"Go":         true,
-		"HTML":       true,
-		"Java":       true,
-		"Kotlin":     true,
-		"JavaScript": true,
-		"Python":     true,
-		"Ruby":       true,
-		"Swift":      true,
-		"TypeScript": true,
-		"Vue":        true,
-	}
-}
-
-// IsCodeQLLanguage filters a map of languages to only include CodeQL-supported languages.
-func IsCodeQLLanguage(languageData map[string]int) map[string]int {
-	allowedLanguages := GetCodeQLLanguages()
-	filteredLanguages := make(map[string]int)
-	for lang, count := range languageData {
-		if allowedLanguages[lang] {
-			filteredLanguages[lang] = count
-		}
-	}
-	return filteredLanguages
-}
-
-// HasCodeQLLanguage checks if a set of languages contains at least one CodeQL-supported language.
-func HasCodeQLLanguage(languages map[string]struct{}) bool {
-	allowedLanguages := GetCodeQLLanguages()
-	for lang := range languages {
-		if allowedLanguages[lang] {
-			return true
+	languages := make(map[string]bool)
+	for lang := range GetCodeQLSupport().Languages {
+		languages[lang] = true
+	}
+	return languages
+}
+
+// addThresholdFlags registers the --min-bytes and --min-share flags on a command.
+func addThresholdFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&min_bytes_flag, "min-bytes", 0, "Minimum bytes of a language before a repository counts toward it")
+	cmd.Flags().Float64Var(&min_share_flag, "min-share", 0, "Minimum share (percent) of a repository's bytes before the repository counts toward a language")
+}
+
+// addPrimaryFlag registers the --primary flag on a command. It must be called after addThresholdFlags,
+// since a repository's primary language is not subject to the language threshold.
+func addPrimaryFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&primary_flag, "primary", false, "Count each repository toward its primary language only (mutually exclusive with --min-bytes, --min-share)")
+	cmd.MarkFlagsMutuallyExclusive("primary", "min-bytes")
+	cmd.MarkFlagsMutuallyExclusive("primary", "min-share")
+}
+
+// ValidateThreshold checks that the language threshold flags are within range.
+func ValidateThreshold(minBytes int, minShare float64) error {
+	if minBytes < 0 {
+		return fmt.Errorf("--min-bytes cannot be negative")
+	}
+	if minShare < 0 || minShare > 100 {
+		return fmt.Errorf("--min-share must be between 0 and 100")
+	}
+	return nil
+}
+
+// LanguagesAboveThreshold returns the languages of a repository that meet the minimum byte count and share.
+func LanguagesAboveThreshold(repo Repository, minBytes int, minShare float64) map[string]struct{} {
+	if minBytes <= 0 && minShare <= 0 {
+		return repo.Languages
+	}
+	languages := make(map[string]struct{})
+	for lang := range repo.Languages {
+		size := repo.LanguageSizes[lang]
+		if size < minBytes {
+			continue
 		}
+		if minShare > 0 && (repo.TotalSize == 0 || float64(size)/float64(repo.TotalSize)*100 < minShare) {
+			continue
+		}
+		languages[lang] = struct{}{}
 	}
-	return false
+	return languages
+}
+
+// RepositoryLanguages returns the languages a repository counts toward: only its primary language
+// (or NO_PRIMARY_LANGUAGE) in primary mode, otherwise every language that meets the threshold.
+func RepositoryLanguages(repo Repository, primary bool, minBytes int, minShare float64) map[string]struct{} {
+	if primary {
+		if repo.PrimaryLanguage == "" {
+			return map[string]struct{}{NO_PRIMARY_LANGUAGE: {}}
+		}
+		return map[string]struct{}{repo.PrimaryLanguage: {}}
+	}
+	return LanguagesAboveThreshold(repo, minBytes, minShare)
+}
+
+// FormatPercentage formats a part of a total as a percentage. Whole percentages are used unless
+// precise is set, in which case one decimal place is shown so that shares sum to roughly 100%.
+func FormatPercentage(part, total int, precise bool) string {
+	if total == 0 {
+		if precise {
+			return "0.0%"
+		}
+		return "0%"
+	}
+	if precise {
+		return fmt.Sprintf("%.1f%%", float64(part)/float64(total)*100)
+	}
+	return fmt.Sprintf("%d%%", int(float64(part)/float64(total)*100))
+}
+
+// GetThresholdInfo describes the language threshold, or returns an empty string if none is set.
+func GetThresholdInfo(minBytes int, minShare float64) string {
+	switch {
+	case minBytes > 0 && minShare > 0:
+		return fmt.Sprintf("Language threshold: at least %d bytes and %g%% of a repository", minBytes, minShare)
+	case minBytes > 0:
+		return fmt.Sprintf("Language threshold: at least %d bytes of a repository", minBytes)
+	case minShare > 0:
+		return fmt.Sprintf("Language threshold: at least %g%% of a repository", minShare)
+	}
+	return ""
 }
 
 // PrintInfo prints an informational message with pterm.
@@ -256,6 +455,14 @@ func PrintTotalOrganizations(total int) {
 	PrintInfoWithFormat("Total number of organizations found: %d", total)
 }
 
+// PrintSkippedOrganizations prints the organizations that were skipped by the organization filter.
+func PrintSkippedOrganizations(skipped []string) {
+	if len(skipped) == 0 {
+		return
+	}
+	PrintInfoWithFormat("Skipped organizations (%d): %s", len(skipped), strings.Join(skipped, ", "))
+}
+
 // PrintTotalRepositories prints the total number of repositories analyzed.
 func PrintTotalRepositories(total int) {
 	pterm.Println() // Add a new line
@@ -289,6 +496,17 @@ func MatchesLanguageFilter(lang string, languages []string) bool {
 	return false
 }
 
+// KeepLanguage reports whether a language passes the --language, --preset (or --codeql), --exclude-language and --type filters.
+func KeepLanguage(lang string, languages []string, preset *Preset, excluded []string, types []string) bool {
+	if len(languages) > 0 && !MatchesLanguageFilter(lang, languages) {
+		return false
+	}
+	if preset != nil && !preset.Has(lang) {
+		return false
+	}
+	return !IsExcludedLanguage(lang, excluded, types)
+}
+
 // ValidateFlags checks if the required flags are set and returns an error if not.
 func ValidateFlags(org, enterprise string) error {
 	if org == "" && enterprise == "" {
@@ -297,16 +515,6 @@ func ValidateFlags(org, enterprise string) error {
 	return nil
 }
 
-// GetLanguageFilter determines the language filter info based on flags.
-func GetLanguageFilter(codeqlFlag bool, language string, top int) string {
-	if codeqlFlag {
-		return "CodeQL language filter applied"
-	} else if language != "" {
-		return fmt.Sprintf("Language filter: %s", language)
-	}
-	return fmt.Sprintf("Top languages limit: %d", top)
-}
-
 // FetchLanguages fetches the programming languages used in a repository.
 func FetchLanguages(client *api.RESTClient, org, repo string) (map[string]int, error) {
 	if org == "" || repo == "" {
@@ -345,22 +553,143 @@ func FetchLanguages(client *api.RESTClient, org, repo string) (map[string]int, e
 	return languages, nil
 }
 
+// Repository holds the repository metadata and language breakdown returned by the GraphQL API.
+type Repository struct {
+	Org             string              `json:"org"`
+	Name            string              `json:"name"`
+	CreatedAt       string              `json:"created_at"`
+	PushedAt        string              `json:"pushed_at"`
+	UpdatedAt       string              `json:"updated_at"`
+	ArchivedAt      string              `json:"archived_at"`
+	URL             string              `json:"html_url"`
+	Visibility      string              `json:"visibility"`
+	IsArchived      bool                `json:"archived"`
+	Topics          []string            `json:"topics"`
+	PrimaryLanguage string              `json:"primary_language"`
+	DiskUsage       int                 `json:"size"` // in kilobytes, named "size" by the REST API
+	Languages       map[string]struct{} `json:"languages"`
+	LanguageSizes   map[string]int      `json:"language_sizes"`
+	TotalSize       int                 `json:"total_size"`
+	// LanguagesUnknown is set in snapshots for repositories whose languages could not be fetched.
+	LanguagesUnknown bool `json:"languages_unknown,omitempty"`
+}
+
+// RepositoryGraphQLFields selects the repository fields decoded into graphQLRepository. Fields that are only
+// needed by some flags are only requested with them, as they add to the cost of every page and archivedAt is
+// missing from the schema of older GitHub Enterprise Server versions.
+func RepositoryGraphQLFields() string {
+	fields := []string{"name", "createdAt", "pushedAt", "updatedAt", "url", "diskUsage", "isArchived"}
+	if date_field_flag == "archived" {
+		fields = append(fields, "archivedAt")
+	}
+	for _, dimension := range strings.Split(group_by_flag, ",") {
+		switch strings.TrimSpace(dimension) {
+		case "visibility":
+			fields = append(fields, "visibility")
+		case "topic":
+			fields = append(fields, `repositoryTopics(first: 20) {
+							nodes {
+								topic {
+									name
+								}
+							}
+						}`)
+		}
+	}
+	fields = append(fields, `primaryLanguage {
+							name
+						}
+						languages(first: 100) {
+							edges {
+								size
+								node {
+									name
+								}
+							}
+							totalSize
+						}`)
+	return strings.Join(fields, "\n\t\t\t\t\t\t")
+}
+
+// graphQLRepository is a repository as returned by the GraphQL API for RepositoryGraphQLFields.
+type graphQLRepository struct {
+	Name             string `json:"name"`
+	CreatedAt        string `json:"createdAt"`
+	PushedAt         string `json:"pushedAt"`
+	UpdatedAt        string `json:"updatedAt"`
+	ArchivedAt       string `json:"archivedAt"`
+	URL              string `json:"url"`
+	DiskUsage        int    `json:"diskUsage"`
+	Visibility       string `json:"visibility"`
+	IsArchived       bool   `json:"isArchived"`
+	RepositoryTopics struct {
+		Nodes []struct {
+			Topic struct {
+				Name string `json:"name"`
+			} `json:"topic"`
+		} `json:"nodes"`
+	} `json:"repositoryTopics"`
+	PrimaryLanguage *struct {
+		Name string `json:"name"`
+	} `json:"primaryLanguage"`
+	Languages struct {
+		Edges []struct {
+			Size int `json:"size"`
+			Node struct {
+				Name string `json:"name"`
+			} `json:"node"`
+		} `json:"edges"`
+		TotalSize int `json:"totalSize"`
+	} `json:"languages"`
+}
+
+// toRepository converts a GraphQL repository of an organization to a Repository.
+func (repo graphQLRepository) toRepository(org string) Repository {
+	// Convert language edges to maps for compatibility
+	languages := make(map[string]struct{})
+	languageSizes := make(map[string]int)
+	for _, lang := range repo.Languages.Edges {
+		languages[lang.Node.Name] = struct{}{}
+		languageSizes[lang.Node.Name] = lang.Size
+	}
+
+	primaryLanguage := ""
+	if repo.PrimaryLanguage != nil {
+		primaryLanguage = repo.PrimaryLanguage.Name
+	}
+
+	var topics []string
+	for _, node := range repo.RepositoryTopics.Nodes {
+		topics = append(topics, node.Topic.Name)
+	}
+
+	return Repository{
+		Org:             org,
+		Name:            repo.Name,
+		CreatedAt:       repo.CreatedAt,
+		PushedAt:        repo.PushedAt,
+		UpdatedAt:       repo.UpdatedAt,
+		ArchivedAt:      repo.ArchivedAt,
+		URL:             repo.URL,
+		Visibility:      strings.ToLower(repo.Visibility),
+		IsArchived:      repo.IsArchived,
+		Topics:          topics,
+		DiskUsage:       repo.DiskUsage,
+		PrimaryLanguage: primaryLanguage,
+		Languages:       languages,
+		LanguageSizes:   languageSizes,
+		TotalSize:       repo.Languages.TotalSize,
+	}
+}
+
 // FetchRepositoriesGraphQL fetches repositories with languages for a given organization using GraphQL API with pagination.
-func FetchRepositoriesGraphQL(org string, limit int, totalRepos int, hostname string) ([]struct {
-	Name      string              `json:"name"`
-	CreatedAt string              `json:"created_at"`
-	Languages map[string]struct{} `json:"languages"`
-}, error) {
+func FetchRepositoriesGraphQL(org string, limit int, totalRepos int, order RepositoryOrder, hostname string) ([]Repository, error) {
 	if org == "" {
 		return nil, fmt.Errorf("no organization identified, please ensure you have access to the organization or enterprise provided")
 	}
 
 	const maxPerPage = 100
-	var allRepos []struct {
-		Name      string              `json:"name"`
-		CreatedAt string              `json:"created_at"`
-		Languages map[string]struct{} `json:"languages"`
-	}
+	var allRepos []Repository
 
 	var cursor *string
 	fetched := 0
@@ -386,15 +715,9 @@ func FetchRepositoriesGraphQL(org string, limit int, totalRepos int, hostname st
 
 		query := fmt.Sprintf(`{
 			organization(login: "%s") {
-				repositories(first: %d, after: %s) {
+				repositories(first: %d, after: %s%s) {
 					nodes {
-						name
-						createdAt
-						languages(first: 100) {
-							nodes {
-								name
-							}
-						}
+						%s
 					}
 					pageInfo {
 						hasNextPage
@@ -402,7 +725,7 @@ func FetchRepositoriesGraphQL(org string, limit int, totalRepos int, hostname st
 					}
 				}
 			}
-		}`, org, remaining, formatCursor(cursor))
+		}`, org, remaining, formatCursor(cursor), order.GraphQLArgument(), RepositoryGraphQLFields())
 
 		response, stderr, err := gh.Exec("api", "graphql", "--hostname", hostname, "-f", "query="+query)
 		if err != nil {
@@ -417,15 +740,7 @@ func FetchRepositoriesGraphQL(org string, limit int, totalRepos int, hostname st
 			Data struct {
 				Organization struct {
 					Repositories struct {
-						Nodes []struct {
-							Name      string `json:"name"`
-							CreatedAt string `json:"createdAt"`
-							Languages struct {
-								Nodes []struct {
-									Name string `json:"name"`
-								} `json:"nodes"`
-							} `json:"languages"`
-						} `json:"nodes"`
+						Nodes    []graphQLRepository `json:"nodes"`
 						PageInfo struct {
 							HasNextPage bool   `json:"hasNextPage"`
 							EndCursor   string `json:"endCursor"`
@@ -451,21 +766,7 @@ func FetchRepositoriesGraphQL(org string, limit int, totalRepos int, hostname st
 		// Process repositories from this page
 		reposInThisPage := 0
 		for _, repo := range result.Data.Organization.Repositories.Nodes {
-			// Convert language nodes to map for compatibility
-			languages := make(map[string]struct{})
-			for _, lang := range repo.Languages.Nodes {
-				languages[lang.Name] = struct{}{}
-			}
-
-			allRepos = append(allRepos, struct {
-				Name      string              `json:"name"`
-				CreatedAt string              `json:"created_at"`
-				Languages map[string]struct{} `json:"languages"`
-			}{
-				Name:      repo.Name,
-				CreatedAt: repo.CreatedAt,
-				Languages: languages,
-			})
+			allRepos = append(allRepos, repo.toRepository(org))
 
 			fetched++
 			reposInThisPage++
@@ -489,6 +790,39 @@ func FetchRepositoriesGraphQL(org string, limit int, totalRepos int, hostname st
 	return allRepos, nil
 }
 
+// IndexOrganizationRepositories counts the repositories of an organization and fetches them with their
+// languages using the GraphQL API, reporting progress with a spinner and a progress bar.
+func IndexOrganizationRepositories(org string, orgIndex, orgCount, repoLimit int, order RepositoryOrder, hostname string) ([]Repository, error) {
+	// Start a spinner to indicate progress for indexing the organization.
+	spinnerInfo, _ := pterm.DefaultSpinner.Start(fmt.Sprintf("Indexing organization: %s", org))
+
+	// First, count the total number of repositories in the organization
+	totalReposInOrg, err := CountRepositoriesGraphQL(org, hostname)
+	if err != nil {
+		// Stop the spinner and indicate failure if an error occurs.
+		spinnerInfo.Fail("Failed to index organization")
+		return nil, err
+	}
+
+	if totalReposInOrg == 0 {
+		// Stop the spinner and indicate a warning if no repositories are found.
+		spinnerInfo.Warning(fmt.Sprintf("No repositories found for organization %d of %d: %s", orgIndex+1, orgCount, org))
+		return nil, nil
+	}
+
+	// Apply the repo limit to determine effective repository count
+	effectiveRepoCount := totalReposInOrg
+	if repoLimit < totalReposInOrg {
+		effectiveRepoCount = repoLimit
+	}
+
+	// Stop the spinner and indicate success.
+	spinnerInfo.Success(fmt.Sprintf("Successfully indexed organization %d of %d: %s (%d repositories, limited to %d)", orgIndex+1, orgCount, org, totalReposInOrg, effectiveRepoCount))
+
+	// Fetch repositories with languages using GraphQL API with progress bar.
+	return FetchRepositoriesGraphQL(org, repoLimit, totalReposInOrg, order, hostname)
+}
+
 // CountRepositoriesGraphQL counts the total number of repositories in an organization using GraphQL API.
 func CountRepositoriesGraphQL(org string, hostname string) (int, error) {
 	if org == "" {
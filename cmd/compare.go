@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// COMPARE_SCOPE_KINDS lists the kinds of scope accepted by compare --a and --b.
+var COMPARE_SCOPE_KINDS = []string{"org", "enterprise"}
+
+var compare_a_flag string
+var compare_b_flag string
+
+func init() {
+	compareCmd.Flags().StringVar(&compare_a_flag, "a", "", "The first scope to compare, as org:<name> or enterprise:<slug>")
+	compareCmd.Flags().StringVar(&compare_b_flag, "b", "", "The second scope to compare, as org:<name> or enterprise:<slug>")
+	compareCmd.MarkFlagRequired("a")
+	compareCmd.MarkFlagRequired("b")
+	addThresholdFlags(compareCmd)
+	addPrimaryFlag(compareCmd)
+}
+
+var compareCmd = &cobra.Command{
+	Use:   "compare",
+	Short: "Compare the programming languages used in repos across two organizations or enterprises side by side",
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		return runCompare(cmd, args)
+	},
+}
+
+// compareScope is one side of a comparison: an organization or an enterprise, with its language counts.
+type compareScope struct {
+	Label      string
+	Org        string
+	Enterprise string
+	Repos      int
+	Languages  map[string]int
+}
+
+// ParseCompareScope parses a scope given as org:<name> or enterprise:<slug>.
+func ParseCompareScope(scope string) (*compareScope, error) {
+	kind, name, found := strings.Cut(strings.TrimSpace(scope), ":")
+	if !found || name == "" || !MatchesLanguageFilter(kind, COMPARE_SCOPE_KINDS) {
+		return nil, fmt.Errorf("invalid scope '%s'. Use org:<name> or enterprise:<slug>", scope)
+	}
+	parsed := &compareScope{Label: scope, Languages: make(map[string]int)}
+	if kind == "org" {
+		parsed.Org = name
+	} else {
+		parsed.Enterprise = name
+	}
+	return parsed, nil
+}
+
+// share returns the percentage of the scope's repositories that use a language.
+func (s *compareScope) share(lang string) float64 {
+	return periodShare(s.Languages[lang], s.Repos)
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	repoLimit := repo_limit_flag
+	orgLimit := org_limit_flag
+	hostname := github_enterprise_server_url_flag
+
+	a, err := ParseCompareScope(compare_a_flag)
+	if err != nil {
+		return fmt.Errorf("--a: %v", err)
+	}
+	b, err := ParseCompareScope(compare_b_flag)
+	if err != nil {
+		return fmt.Errorf("--b: %v", err)
+	}
+
+	pipeline, err := LanguagePipelineFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	order, err := ParseRepositoryOrder(order_by_flag, order_flag)
+	if err != nil {
+		return err
+	}
+
+	sizeFilter, err := NewSizeFilter(min_size_flag, max_size_flag)
+	if err != nil {
+		return err
+	}
+
+	orgFilter, err := ParseOrgFilter(org_include_flag, org_exclude_flag, orgs_file_flag)
+	if err != nil {
+		return err
+	}
+
+	// Describe the language filters in the order they are applied.
+	languageFilter := pipeline.String()
+	if !sizeFilter.IsEmpty() {
+		languageFilter += ", " + sizeFilter.String()
+	}
+	if !order.IsEmpty() {
+		languageFilter += ", " + order.String()
+	}
+
+	// Fetch and count each side in turn.
+	for _, scope := range []*compareScope{a, b} {
+		pterm.DefaultSection.Println(fmt.Sprintf("Indexing %s", scope.Label))
+
+		// The organization filter only applies to enterprise scopes.
+		scopeOrgFilter := orgFilter
+		if scope.Enterprise == "" {
+			scopeOrgFilter = OrgFilter{}
+		}
+		orgs, err := ResolveOrganizations(scope.Enterprise, scope.Org, orgLimit, repoLimit, languageFilter, scopeOrgFilter, hostname)
+		if err != nil {
+			return err
+		}
+
+		for orgIndex, org := range orgs {
+			// Count and fetch the repositories of the organization with their languages.
+			repos, err := IndexOrganizationRepositories(org, orgIndex, len(orgs), repoLimit, order, hostname)
+			if err != nil {
+				return err
+			}
+
+			// Drop repositories outside the --min-size and --max-size range.
+			repos = sizeFilter.Apply(repos)
+
+			// Increment the total repository count of the side.
+			scope.Repos += len(repos)
+
+			for _, repo := range repos {
+				// Only count the primary language with --primary, otherwise languages that pass the filters and threshold.
+				for lang := range pipeline.RepositoryLanguages(repo) {
+					scope.Languages[lang]++
+				}
+			}
+		}
+		pterm.Println()
+	}
+
+	// Print the total number of repositories analyzed on each side.
+	pterm.Info.Println(fmt.Sprintf("Total number of repositories analyzed: %d (A: %s), %d (B: %s)", a.Repos, a.Label, b.Repos, b.Label))
+	PrintSizeFilterSummary(sizeFilter)
+	// Print the language threshold so that results remain comparable across runs.
+	if thresholdInfo := GetThresholdInfo(min_bytes_flag, min_share_flag); thresholdInfo != "" {
+		pterm.Info.Println(thresholdInfo)
+	}
+	pipeline.WarnUnknownTypes()
+	// In primary mode each repository contributes to exactly one language.
+	if primary_flag {
+		pterm.Info.Println("Primary language mode: each repository counts toward its primary language only")
+	}
+	pterm.Println()
+
+	// Rank languages by their combined count across both sides, limited to the top N languages.
+	combined := make(map[string]int)
+	for lang, count := range a.Languages {
+		combined[lang] += count
+	}
+	for lang, count := range b.Languages {
+		combined[lang] += count
+	}
+	topLangs := topLanguageNames(pipeline.SelectTop(combined), "", 0)
+
+	pterm.DefaultSection.Println(fmt.Sprintf("Language Comparison (A: %s, B: %s)", a.Label, b.Label))
+	rows := [][]string{{"Language", "A Count", "A Percentage", "B Count", "B Percentage", "Trend", "B − A (pp)", "Only In"}}
+	for _, lang := range topLangs {
+		arrow, difference := trendIndicator(b.share(lang), a.share(lang), 1, "pp")
+		onlyIn := ""
+		switch {
+		case b.Languages[lang] == 0:
+			onlyIn = pterm.Yellow("A")
+		case a.Languages[lang] == 0:
+			onlyIn = pterm.Yellow("B")
+		}
+		rows = append(rows, []string{
+			lang,
+			fmt.Sprintf("%d", a.Languages[lang]),
+			FormatPercentage(a.Languages[lang], a.Repos, true),
+			fmt.Sprintf("%d", b.Languages[lang]),
+			FormatPercentage(b.Languages[lang], b.Repos, true),
+			arrow,
+			difference,
+			onlyIn,
+		})
+	}
+	pterm.DefaultTable.WithHasHeader(true).WithData(rows).Render()
+	pterm.Println()
+
+	// List every language found on one side only, including those beyond the top N.
+	var onlyA, onlyB []string
+	for _, lang := range topLanguageNames(combined, "", 0) {
+		switch {
+		case b.Languages[lang] == 0:
+			onlyA = append(onlyA, lang)
+		case a.Languages[lang] == 0:
+			onlyB = append(onlyB, lang)
+		}
+	}
+	if len(onlyA) > 0 {
+		pterm.Info.Println(fmt.Sprintf("Languages only in A (%s): %s", a.Label, strings.Join(onlyA, ", ")))
+	}
+	if len(onlyB) > 0 {
+		pterm.Info.Println(fmt.Sprintf("Languages only in B (%s): %s", b.Label, strings.Join(onlyB, ", ")))
+	}
+
+	return nil
+}
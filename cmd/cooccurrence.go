@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var stacks_flag int
+
+func init() {
+	addThresholdFlags(cooccurrenceCmd)
+	cooccurrenceCmd.Flags().IntVar(&stacks_flag, "stacks", 10, "The number of most common language stacks (exact language combinations) to display")
+}
+
+var cooccurrenceCmd = &cobra.Command{
+	Use:   "cooccurrence",
+	Short: "Analyze which programming languages are used together in repos across an enterprise or organization",
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		return runCooccurrence(cmd, args)
+	},
+}
+
+// languagePair holds the co-occurrence statistics of two languages.
+type languagePair struct {
+	A       string
+	B       string
+	Both    int
+	Jaccard float64
+	Lift    float64
+}
+
+// languageStack returns the canonical name of an exact language combination, with languages sorted alphabetically.
+func languageStack(languages map[string]struct{}) string {
+	names := make([]string, 0, len(languages))
+	for lang := range languages {
+		names = append(names, lang)
+	}
+	sort.Strings(names)
+	return strings.Join(names, " + ")
+}
+
+func runCooccurrence(cmd *cobra.Command, args []string) error {
+	org := org_flag
+	enterprise := enterprise_flag
+	repoLimit := repo_limit_flag
+	orgLimit := org_limit_flag
+	hostname := github_enterprise_server_url_flag
+
+	if err := ValidateFlags(org, enterprise); err != nil {
+		return err
+	}
+
+	pipeline, err := LanguagePipelineFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	order, err := ParseRepositoryOrder(order_by_flag, order_flag)
+	if err != nil {
+		return err
+	}
+
+	sizeFilter, err := NewSizeFilter(min_size_flag, max_size_flag)
+	if err != nil {
+		return err
+	}
+
+	orgFilter, err := ParseOrgFilter(org_include_flag, org_exclude_flag, orgs_file_flag)
+	if err != nil {
+		return err
+	}
+
+	// Describe the language filters in the order they are applied.
+	languageFilter := pipeline.String()
+	if !sizeFilter.IsEmpty() {
+		languageFilter += ", " + sizeFilter.String()
+	}
+	if !order.IsEmpty() {
+		languageFilter += ", " + order.String()
+	}
+	orgs, err := ResolveOrganizations(enterprise, org, orgLimit, repoLimit, languageFilter, orgFilter, hostname)
+	if err != nil {
+		return err
+	}
+
+	// languageData counts the repositories containing each language, pairData the repositories
+	// containing both languages of a pair, and stackData the repositories per exact language combination.
+	languageData := make(map[string]int)
+	pairData := make(map[string]map[string]int)
+	stackData := make(map[string]int)
+	var totalRepos int
+
+	// Iterate over each organization to fetch repositories and analyze languages.
+	for orgIndex, org := range orgs {
+		// Count and fetch the repositories of the organization with their languages.
+		repos, err := IndexOrganizationRepositories(org, orgIndex, len(orgs), repoLimit, order, hostname)
+		if err != nil {
+			return err
+		}
+
+		// Drop repositories outside the --min-size and --max-size range.
+		repos = sizeFilter.Apply(repos)
+
+		// Increment the total repository count.
+		totalRepos += len(repos)
+
+		for _, repo := range repos {
+			// Keep the languages that meet the threshold and pass the language filters.
+			repoLanguages := pipeline.RepositoryLanguages(repo)
+			if len(repoLanguages) == 0 {
+				continue
+			}
+
+			stackData[languageStack(repoLanguages)]++
+			for a := range repoLanguages {
+				languageData[a]++
+				if pairData[a] == nil {
+					pairData[a] = make(map[string]int)
+				}
+				for b := range repoLanguages {
+					if a != b {
+						pairData[a][b]++
+					}
+				}
+			}
+		}
+	}
+
+	// Print the total number of repositories analyzed.
+	pterm.Println() // Add a new line
+	pterm.Info.Println(fmt.Sprintf("Total number of repositories analyzed: %d", totalRepos))
+	PrintSizeFilterSummary(sizeFilter)
+	// Print the language threshold so that results remain comparable across runs.
+	if thresholdInfo := GetThresholdInfo(min_bytes_flag, min_share_flag); thresholdInfo != "" {
+		pterm.Info.Println(thresholdInfo)
+	}
+	pipeline.WarnUnknownTypes()
+	pterm.Println() // Add a new line
+
+	if totalRepos == 0 {
+		return nil
+	}
+
+	// Limit the matrix to the top N languages (or all languages passing the filters).
+	topLangs := topLanguageNames(pipeline.SelectTop(languageData), "", 0)
+
+	// ── Section 1: Co-occurrence Matrix ─────────────────────────────
+	// Symmetric matrix of repos containing both languages; the diagonal holds repos containing the language.
+	pterm.DefaultSection.Println("Language Co-occurrence (Repos Containing Both Languages)")
+	matrix := [][]string{append([]string{"Language"}, topLangs...)}
+	for _, a := range topLangs {
+		row := []string{a}
+		for _, b := range topLangs {
+			if a == b {
+				row = append(row, pterm.Bold.Sprint(languageData[a]))
+			} else {
+				row = append(row, fmt.Sprintf("%d", pairData[a][b]))
+			}
+		}
+		matrix = append(matrix, row)
+	}
+	pterm.DefaultTable.WithHasHeader(true).WithData(matrix).Render()
+	pterm.Println()
+
+	// ── Section 2: Language Pairs ───────────────────────────────────
+	// Jaccard = both / either; lift = observed co-occurrence / co-occurrence expected if independent.
+	pairs := make([]languagePair, 0)
+	for i, a := range topLangs {
+		for _, b := range topLangs[i+1:] {
+			both := pairData[a][b]
+			if both == 0 {
+				continue
+			}
+			pairs = append(pairs, languagePair{
+				A:       a,
+				B:       b,
+				Both:    both,
+				Jaccard: float64(both) / float64(languageData[a]+languageData[b]-both),
+				Lift:    float64(both) * float64(totalRepos) / (float64(languageData[a]) * float64(languageData[b])),
+			})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Both != pairs[j].Both {
+			return pairs[i].Both > pairs[j].Both
+		}
+		return pairs[i].Lift > pairs[j].Lift
+	})
+
+	pterm.DefaultSection.Println("Language Pairs")
+	pairRows := [][]string{{"Language A", "Language B", "Both", "Jaccard", "Lift"}}
+	for _, pair := range pairs {
+		pairRows = append(pairRows, []string{
+			pair.A,
+			pair.B,
+			fmt.Sprintf("%d", pair.Both),
+			fmt.Sprintf("%.2f", pair.Jaccard),
+			fmt.Sprintf("%.2f", pair.Lift),
+		})
+	}
+	pterm.DefaultTable.WithHasHeader(true).WithData(pairRows).Render()
+	pterm.Println()
+
+	// ── Section 3: Most Common Language Stacks ──────────────────────
+	// Exact language combinations, counted across all languages that pass the filters.
+	pterm.DefaultSection.Println("Most Common Language Stacks")
+	stackRows := [][]string{{"Stack", "Count", "Percentage"}}
+	for _, stack := range topLanguageNames(stackData, "", stacks_flag) {
+		stackRows = append(stackRows, []string{stack, fmt.Sprintf("%d", stackData[stack]), FormatPercentage(stackData[stack], totalRepos, false)})
+	}
+	pterm.DefaultTable.WithHasHeader(true).WithData(stackRows).Render()
+
+	return nil
+}
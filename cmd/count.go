@@ -3,7 +3,9 @@ package cmd
 import (
 	"fmt"
 	"sort"
+	"strings"
 
+	"github.com/cli/go-gh/v2/pkg/api"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 )
@@ -16,93 +18,174 @@ var countCmd = &cobra.Command{
 	},
 }
 
+func init() {
+	addThresholdFlags(countCmd)
+	addPrimaryFlag(countCmd)
+	addGroupByFlag(countCmd)
+	addSaveFlag(countCmd)
+	addBenchmarkFlags(countCmd)
+	addSampleFlags(countCmd)
+	addCodeQLByFlag(countCmd)
+}
+
 func runCount(cmd *cobra.Command, args []string) error {
 	org := org_flag
 	enterprise := enterprise_flag
 	repoLimit := repo_limit_flag
 	orgLimit := org_limit_flag
-	top := top_flag
-	language := language_flag
+	preset := activePreset
 	hostname := github_enterprise_server_url_flag
 
 	if err := ValidateFlags(org, enterprise); err != nil {
 		return err
 	}
 
-	var orgs []string
+	pipeline, err := LanguagePipelineFromFlags(cmd)
+	if err != nil {
+		return err
+	}
 
-	if enterprise != "" {
-		// Determine the language filter or top languages info based on flags.
-		languageFilter := GetLanguageFilter(codeql_flag, language, top)
-		// Print organization and repository limits along with the language filter.
-		PrintInfoWithFormat("Organization limit: %d, Repository limit: %d, %s", orgLimit, repoLimit, languageFilter)
-		spinnerEnterprise, _ := StartIndexingEnterpriseSpinner(enterprise)
-		var err error
-		orgs, err = FetchOrganizations(enterprise, orgLimit, hostname)
-		if err != nil {
-			spinnerEnterprise.Fail("Failed to index organizations for enterprise")
+	groupBy, err := ParseGroupBy(group_by_flag)
+	if err != nil {
+		return err
+	}
+
+	byExtractor, err := ParseCodeQLBy(codeql_by_flag, preset.IsCodeQL())
+	if err != nil {
+		return err
+	}
+
+	// Load the Innovation Graph benchmark up front so that a bad file fails before fetching anything.
+	var benchmark *Benchmark
+	if benchmark_flag != "" {
+		// Innovation Graph data is reported by Linguist language only.
+		if byExtractor {
+			return fmt.Errorf("--benchmark cannot be used with --codeql-by extractor")
+		}
+		if benchmark, err = LoadBenchmark(benchmark_flag, benchmark_economy_flag); err != nil {
+			return err
+		}
+	}
+
+	sampler, err := SamplerFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	order, err := ParseRepositoryOrder(order_by_flag, order_flag)
+	if err != nil {
+		return err
+	}
+
+	sizeFilter, err := NewSizeFilter(min_size_flag, max_size_flag)
+	if err != nil {
+		return err
+	}
+
+	orgFilter, err := ParseOrgFilter(org_include_flag, org_exclude_flag, orgs_file_flag)
+	if err != nil {
+		return err
+	}
+
+	// Describe the language filters in the order they are applied.
+	languageFilter := pipeline.String()
+	if byExtractor {
+		languageFilter += ", Reported by CodeQL extractor"
+	}
+	if !sizeFilter.IsEmpty() {
+		languageFilter += ", " + sizeFilter.String()
+	}
+	if !order.IsEmpty() {
+		languageFilter += ", " + order.String()
+	}
+	if sampler != nil {
+		languageFilter += ", " + sampler.String()
+	}
+	orgs, err := ResolveOrganizations(enterprise, org, orgLimit, repoLimit, languageFilter, orgFilter, hostname)
+	if err != nil {
+		return err
+	}
+
+	// Draw the sample from the repositories of all organizations at once, so that it can be pooled.
+	if sampler != nil {
+		if err := sampler.Plan(orgs, hostname); err != nil {
 			return err
 		}
-		spinnerEnterprise.Success(fmt.Sprintf("Successfully indexed enterprise: %s", enterprise))
-		PrintTotalOrganizations(len(orgs))
-	} else {
-		// Handle the case where only a single organization is provided.
-		topLanguagesInfo := GetLanguageFilter(codeql_flag, language, top)
-		PrintInfoWithFormat("Repository limit: %d, %s", repoLimit, topLanguagesInfo)
-		orgs = []string{org}
 	}
 
 	// Initialize a map to store language data and a counter for total repositories.
 	languageData := make(map[string]int)
 	var totalRepos int
-	var codeqlRepos int
+	var presetRepos int
+	// Count the repositories per number of CodeQL extractors with --codeql-by extractor.
+	extractorHistogram := make(map[int]int)
+
+	// Initialize the per-group language data if --group-by is set.
+	groupContext := NewGroupContext(groupBy, hostname)
+	groupedData := NewGroupedLanguageData()
+	var client *api.RESTClient
+	if !groupContext.IsEmpty() || sampler != nil {
+		// Owning teams, custom properties and repositories by page number are only available through the REST API.
+		client, err = CreateRESTClient(hostname)
+		if err != nil {
+			pterm.Error.Println("Failed to create REST client:", err)
+			return err
+		}
+	}
+
+	// Keep the analyzed repositories for --save.
+	var savedRepos []Repository
 
 	// Iterate over each organization to fetch repositories and analyze languages.
 	for orgIndex, org := range orgs {
-		// Start a spinner to indicate progress for indexing the organization.
-		spinnerInfo, _ := pterm.DefaultSpinner.Start(fmt.Sprintf("Indexing organization: %s", org))
-
-		// First, count the total number of repositories in the organization
-		totalReposInOrg, err := CountRepositoriesGraphQL(org, hostname)
+		// Count and fetch the repositories of the organization (or a random sample of them) with their languages.
+		repos, err := LoadOrganizationRepositories(client, org, orgIndex, len(orgs), repoLimit, order, sampler, hostname)
 		if err != nil {
-			// Stop the spinner and indicate failure if an error occurs.
-			spinnerInfo.Fail("Failed to index organization")
 			return err
 		}
-
-		if totalReposInOrg == 0 {
-			// Stop the spinner and indicate a warning if no repositories are found.
-			spinnerInfo.Warning(fmt.Sprintf("No repositories found for organization %d of %d: %s", orgIndex+1, len(orgs), org))
+		if len(repos) == 0 {
 			continue
 		}
 
-		// Apply the repo limit to determine effective repository count
-		effectiveRepoCount := totalReposInOrg
-		if repoLimit < totalReposInOrg {
-			effectiveRepoCount = repoLimit
-		}
-
-		// Stop the spinner and indicate success.
-		spinnerInfo.Success(fmt.Sprintf("Successfully indexed organization %d of %d: %s (%d repositories, limited to %d)", orgIndex+1, len(orgs), org, totalReposInOrg, effectiveRepoCount))
+		// Drop repositories outside the --min-size and --max-size range.
+		repos = sizeFilter.Apply(repos)
 
-		// Fetch repositories with languages using GraphQL API with progress bar.
-		repos, err := FetchRepositoriesGraphQL(org, repoLimit, totalReposInOrg, hostname)
-		if err != nil {
-			return err
+		// Fetch the organization data needed to group repositories, if any.
+		if !groupContext.IsEmpty() {
+			if err := groupContext.LoadOrganization(client, org); err != nil {
+				return err
+			}
 		}
 
 		// Increment the total repository count.
 		totalRepos += len(repos)
+		savedRepos = append(savedRepos, repos...)
 
 		// Analyze each repository for language usage.
 		for _, repo := range repos {
+			// Only count the primary language with --primary, otherwise languages that pass the filters and threshold.
+			repoLanguages := pipeline.RepositoryLanguages(repo)
+			// Track repos with at least one language supported by the preset.
+			if preset != nil && preset.Matches(repoLanguages) {
+				presetRepos++
+			}
+			// Count the CodeQL extractors that analyze the languages instead with --codeql-by extractor.
+			if byExtractor {
+				repoLanguages = CodeQLExtractorSet(repoLanguages)
+				extractorHistogram[len(repoLanguages)]++
+			}
 			// Update the language data map with the fetched data by incrementing the count.
-			for lang := range repo.Languages {
+			for lang := range repoLanguages {
 				languageData[lang]++
 			}
-			// Track repos with at least one CodeQL-supported language.
-			if codeql_flag && HasCodeQLLanguage(repo.Languages) {
-				codeqlRepos++
+			// Update the per-group language data if --group-by is set.
+			if !groupContext.IsEmpty() {
+				for _, group := range groupContext.GroupKeys(repo) {
+					groupedData.Totals[group]++
+					for lang := range repoLanguages {
+						groupedData.Add(group, lang, 1)
+					}
+				}
 			}
 		}
 	}
@@ -110,61 +193,65 @@ func runCount(cmd *cobra.Command, args []string) error {
 	// Print the total number of repositories analyzed.
 	pterm.Println() // Add a new line
 	pterm.Info.Println(fmt.Sprintf("Total number of repositories analyzed: %d", totalRepos))
-
-	// Print the number of unique repos with at least one CodeQL-supported language.
-	if codeql_flag {
-		pterm.Info.Println(fmt.Sprintf("Unique repositories with at least one CodeQL-supported language: %d", codeqlRepos))
+	if sampler != nil {
+		pterm.Info.Println(fmt.Sprintf("Sampled %d of %d repositories (seed %d). Percentages are estimates with 95%% confidence intervals", sampler.Sampled, sampler.Population, sampler.Seed))
+	}
+	PrintSizeFilterSummary(sizeFilter)
+	if err := SaveSnapshot("count", hostname, enterprise, orgs, savedRepos, sampler); err != nil {
+		return err
 	}
-	pterm.Println() // Add a new line
 
-	// Filter language data if specific languages are specified.
-	if language != "" {
-		languages := ParseLanguages(language)
-		filteredLanguageData := make(map[string]int)
-		for lang, count := range languageData {
-			if MatchesLanguageFilter(lang, languages) {
-				filteredLanguageData[lang] = count
-			}
-		}
-		languageData = filteredLanguageData
+	// Print the language threshold so that results remain comparable across runs.
+	if thresholdInfo := GetThresholdInfo(min_bytes_flag, min_share_flag); thresholdInfo != "" {
+		pterm.Info.Println(thresholdInfo)
 	}
+	pipeline.WarnUnknownTypes()
 
-	// Respect the --top flag by limiting the number of languages displayed.
-	if top > 0 {
-		// Sort the languages by their usage count in descending order.
-		sortedLanguages := make([]struct {
-			Language string
-			Count    int
-		}, 0, len(languageData))
+	// In primary mode each repository contributes to exactly one language.
+	if primary_flag {
+		pterm.Info.Println("Primary language mode: each repository counts toward its primary language only")
+	}
 
-		for lang, count := range languageData {
-			sortedLanguages = append(sortedLanguages, struct {
-				Language string
-				Count    int
-			}{lang, count})
-		}
+	// Print the number of unique repos with at least one language supported by the preset.
+	if preset != nil {
+		pterm.Info.Println(fmt.Sprintf("Unique repositories with at least one %s-supported language: %d", preset.Title, presetRepos))
+	}
+	pterm.Println() // Add a new line
 
-		sort.Slice(sortedLanguages, func(i, j int) bool {
-			return sortedLanguages[i].Count > sortedLanguages[j].Count
-		})
+	// Benchmark shares are taken of the whole language mix, before the top N are selected.
+	mixTotal := LanguageMixTotal(languageData)
 
-		// Select the top N languages based on the --top flag.
-		topLanguages := make(map[string]int)
-		for i := 0; i < top && i < len(sortedLanguages); i++ {
-			topLanguages[sortedLanguages[i].Language] = sortedLanguages[i].Count
-		}
+	// The languages were filtered per repository, so only the top N remain to be selected.
+	languageData = pipeline.SelectTop(languageData)
 
-		languageData = topLanguages
+	// Benchmark against the most recent year of Innovation Graph data.
+	var benchmarkFirst, benchmarkLast int
+	if benchmark != nil {
+		benchmarkLast = benchmark.LatestQuarter()
+		benchmarkFirst = benchmarkLast - BENCHMARK_QUARTERS + 1
+		pterm.Info.Println(fmt.Sprintf("Benchmark: GitHub Innovation Graph (%s), share of developers pushing code, %s → %s", benchmark.Name(), periodLabel(benchmarkFirst, "quarter"), periodLabel(benchmarkLast, "quarter")))
+		pterm.Info.Println(BenchmarkBasis("repos"))
+		pterm.Println() // Add a new line
 	}
 
-	// Filter language data to include only CodeQL-supported languages if the flag is set.
-	if codeql_flag {
-		languageData = IsCodeQLLanguage(languageData)
+	// Percentages estimated from a sample show their 95% confidence interval.
+	formatPercentage := func(part, total int) string {
+		if sampler != nil {
+			return sampler.FormatPercentageCI(part, total, primary_flag)
+		}
+		return FormatPercentage(part, total, primary_flag)
 	}
 
 	// Render the language data as a table with percentages.
 	pterm.DefaultTable.WithHasHeader(true).WithData(func() [][]string {
-		rows := [][]string{{"Language", "Count", "Percentage"}}
+		header := []string{"Language", "Count", "Percentage"}
+		if byExtractor {
+			header[0] = "Extractor"
+		}
+		if benchmark != nil {
+			header = append(header, "Mix Share", fmt.Sprintf("%s Share", benchmark.Name()), "Index")
+		}
+		rows := [][]string{header}
 
 		// Sort the languages again for display purposes.
 		sortedLanguages := make([]struct {
@@ -185,12 +272,29 @@ func runCount(cmd *cobra.Command, args []string) error {
 
 		// Calculate and add the percentage for each language.
 		for _, langData := range sortedLanguages {
-			percentage := int(float64(langData.Count) / float64(totalRepos) * 100)
-			rows = append(rows, []string{langData.Language, fmt.Sprintf("%d", langData.Count), fmt.Sprintf("%d%%", percentage)})
+			percentage := formatPercentage(langData.Count, totalRepos)
+			row := []string{langData.Language, fmt.Sprintf("%d", langData.Count), percentage}
+			if benchmark != nil {
+				mixShare, benchmarkShare, index := benchmark.benchmarkColumns(langData.Language, langData.Count, mixTotal, benchmarkFirst, benchmarkLast)
+				row = append(row, mixShare, benchmarkShare, index)
+			}
+			rows = append(rows, row)
 		}
 
 		return rows
 	}()).Render()
 
+	// Render the language × group matrix if --group-by is set.
+	if !groupContext.IsEmpty() {
+		RenderGroupMatrix(fmt.Sprintf("Repositories by %s", strings.Join(groupBy, " / ")), groupedData, topLanguageNames(languageData, "", 0), func(count int) string {
+			return fmt.Sprintf("%d", count)
+		}, formatPercentage)
+	}
+
+	// Render how many CodeQL extractors analyze each repository with --codeql-by extractor.
+	if byExtractor {
+		RenderExtractorHistogram(extractorHistogram, totalRepos)
+	}
+
 	return nil
 }
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
@@ -26,8 +27,7 @@ func runData(cmd *cobra.Command, args []string) error {
 	enterprise := enterprise_flag
 	repoLimit := repo_limit_flag
 	orgLimit := org_limit_flag
-	top := top_flag
-	language := language_flag
+	preset := activePreset
 	unit, _ := cmd.Flags().GetString("unit")
 	hostname := github_enterprise_server_url_flag
 
@@ -35,37 +35,73 @@ func runData(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if unit != "bytes" && unit != "kilobytes" && unit != "megabytes" && unit != "gigabytes" {
-		// Validate the unit flag to ensure it is one of the allowed values.
-		return fmt.Errorf("invalid unit specified. Options are: bytes, kilobytes, megabytes, gigabytes")
+	pipeline, err := LanguagePipelineFromFlags(cmd)
+	if err != nil {
+		return err
 	}
 
-	var orgs []string
+	// Validate the unit flag to ensure it is one of the allowed values.
+	if err := ValidateUnit(unit); err != nil {
+		return err
+	}
 
-	if enterprise != "" {
-		// Determine the language filter or top languages info based on flags.
-		languageFilter := GetLanguageFilter(codeql_flag, language, top)
-		// Print organization and repository limits along with the language filter.
-		PrintInfoWithFormat("Organization limit: %d, Repository limit: %d, %s", orgLimit, repoLimit, languageFilter)
-		spinnerEnterprise, _ := StartIndexingEnterpriseSpinner(enterprise)
-		var err error
-		orgs, err = FetchOrganizations(enterprise, orgLimit, hostname)
-		if err != nil {
-			spinnerEnterprise.Fail("Failed to index organizations for enterprise")
-			return err
-		}
-		spinnerEnterprise.Success(fmt.Sprintf("Successfully indexed enterprise: %s", enterprise))
-		PrintTotalOrganizations(len(orgs))
-	} else {
-		// Handle the case where only a single organization is provided.
-		topLanguagesInfo := GetLanguageFilter(codeql_flag, language, top)
-		PrintInfoWithFormat("Repository limit: %d, %s", repoLimit, topLanguagesInfo)
-		orgs = []string{org}
+	groupBy, err := ParseGroupBy(group_by_flag)
+	if err != nil {
+		return err
+	}
+
+	byExtractor, err := ParseCodeQLBy(codeql_by_flag, preset.IsCodeQL())
+	if err != nil {
+		return err
+	}
+
+	order, err := ParseRepositoryOrder(order_by_flag, order_flag)
+	if err != nil {
+		return err
+	}
+	// The data command fetches repositories with the REST API, which cannot order by every field.
+	if _, err := order.RESTParameters(); err != nil {
+		return err
+	}
+
+	sizeFilter, err := NewSizeFilter(min_size_flag, max_size_flag)
+	if err != nil {
+		return err
+	}
+
+	orgFilter, err := ParseOrgFilter(org_include_flag, org_exclude_flag, orgs_file_flag)
+	if err != nil {
+		return err
+	}
+
+	// Describe the language filters in the order they are applied.
+	languageFilter := pipeline.String()
+	if byExtractor {
+		languageFilter += ", Reported by CodeQL extractor"
+	}
+	if !sizeFilter.IsEmpty() {
+		languageFilter += ", " + sizeFilter.String()
+	}
+	if !order.IsEmpty() {
+		languageFilter += ", " + order.String()
+	}
+	orgs, err := ResolveOrganizations(enterprise, org, orgLimit, repoLimit, languageFilter, orgFilter, hostname)
+	if err != nil {
+		return err
 	}
 
 	// Initialize a map to store language data and a counter for total repositories.
 	languageData := make(map[string]int)
 	var totalRepos int
+	// Count the repositories per number of CodeQL extractors with --codeql-by extractor.
+	extractorHistogram := make(map[int]int)
+
+	// Keep the analyzed repositories with their language sizes for --save.
+	var savedRepos []Repository
+
+	// Initialize the per-group language data if --group-by is set.
+	groupContext := NewGroupContext(groupBy, hostname)
+	groupedData := NewGroupedLanguageData()
 
 	// Create the REST client once.
 	client, err := CreateRESTClient(hostname)
@@ -80,7 +116,7 @@ func runData(cmd *cobra.Command, args []string) error {
 		spinnerInfo, _ := pterm.DefaultSpinner.Start(fmt.Sprintf("Indexing organization: %s", org))
 
 		// Fetch repositories for the organization. This involves a REST API call to GitHub.
-		repos, err := FetchRepositories(client, org, repoLimit)
+		repos, err := FetchRepositories(client, org, repoLimit, order)
 		if err != nil {
 			// Stop the spinner and indicate failure if an error occurs.
 			spinnerInfo.Fail("Failed to index organization")
@@ -95,6 +131,16 @@ func runData(cmd *cobra.Command, args []string) error {
 
 		// Stop the spinner and indicate success.
 		spinnerInfo.Success(fmt.Sprintf("Successfully indexed organization %d of %d: %s", orgIndex+1, len(orgs), org))
+		// Drop repositories outside the --min-size and --max-size range before fetching their languages.
+		repos = sizeFilter.Apply(repos)
+
+		// Fetch the organization data needed to group repositories, if any.
+		if !groupContext.IsEmpty() {
+			if err := groupContext.LoadOrganization(client, org); err != nil {
+				return err
+			}
+		}
+
 		// Start a progress bar for analyzing repositories.
 		progressBar, _ := pterm.DefaultProgressbar.WithTotal(len(repos)).WithTitle("Analyzing repositories").Start()
 
@@ -107,14 +153,39 @@ func runData(cmd *cobra.Command, args []string) error {
 			// Fetch language data for the repository using FetchLanguages.
 			languages, err := FetchLanguages(client, org, repo.Name)
 			if err != nil {
-				// Print a warning and skip the repository if an error occurs.
+				// Print a warning and skip the repository if an error occurs. It is still saved, with unknown
+				// languages, so that the diff command does not report it as removed.
 				pterm.Warning.Println(fmt.Sprintf("Skipping repository %s due to error: %s", repo.Name, err))
+				if save_flag != "" {
+					repo.LanguagesUnknown = true
+					savedRepos = append(savedRepos, repo)
+				}
 				continue
 			}
+			sizedRepo := repo.WithLanguageSizes(languages)
+			if save_flag != "" {
+				savedRepos = append(savedRepos, sizedRepo)
+			}
+			// Keep the bytes of the languages that pass the filters and threshold.
+			languages = pipeline.RepositorySizes(sizedRepo)
+			// Sum the bytes per CodeQL extractor instead with --codeql-by extractor.
+			if byExtractor {
+				languages = CodeQLExtractorSizes(languages)
+				extractorHistogram[len(languages)]++
+			}
 			// Update the language data map with the fetched data.
 			for lang, bytes := range languages {
 				languageData[lang] += bytes
 			}
+			// Update the per-group language data if --group-by is set.
+			if !groupContext.IsEmpty() {
+				for _, group := range groupContext.GroupKeys(repo) {
+					for lang, bytes := range languages {
+						groupedData.Totals[group] += bytes
+						groupedData.Add(group, lang, bytes)
+					}
+				}
+			}
 		}
 
 		// Stop the progress bar after analyzing all repositories.
@@ -124,56 +195,26 @@ func runData(cmd *cobra.Command, args []string) error {
 	// Print the total number of repositories analyzed.
 	pterm.Println() // Add a new line
 	pterm.Info.Println(fmt.Sprintf("Total number of repositories analyzed: %d", totalRepos))
-	pterm.Println() // Add a new line
-
-	// Filter language data if specific languages are specified.
-	if language != "" {
-		languages := ParseLanguages(language)
-		filteredLanguageData := make(map[string]int)
-		for lang, bytes := range languageData {
-			if MatchesLanguageFilter(lang, languages) {
-				filteredLanguageData[lang] = bytes
-			}
-		}
-		languageData = filteredLanguageData
+	PrintSizeFilterSummary(sizeFilter)
+	if err := SaveSnapshot("data", hostname, enterprise, orgs, savedRepos, nil); err != nil {
+		return err
 	}
-
-	// Respect the --top flag by limiting the number of languages displayed.
-	if top > 0 {
-		// Sort the languages by their usage in descending order.
-		sortedLanguages := make([]struct {
-			Language string
-			Bytes    int
-		}, 0, len(languageData))
-
-		for lang, bytes := range languageData {
-			sortedLanguages = append(sortedLanguages, struct {
-				Language string
-				Bytes    int
-			}{lang, bytes})
-		}
-
-		sort.Slice(sortedLanguages, func(i, j int) bool {
-			return sortedLanguages[i].Bytes > sortedLanguages[j].Bytes
-		})
-
-		// Select the top N languages based on the --top flag.
-		topLanguages := make(map[string]int)
-		for i := 0; i < top && i < len(sortedLanguages); i++ {
-			topLanguages[sortedLanguages[i].Language] = sortedLanguages[i].Bytes
-		}
-
-		languageData = topLanguages
+	// Print the language threshold so that results remain comparable across runs.
+	if thresholdInfo := GetThresholdInfo(min_bytes_flag, min_share_flag); thresholdInfo != "" {
+		pterm.Info.Println(thresholdInfo)
 	}
+	pipeline.WarnUnknownTypes()
+	pterm.Println() // Add a new line
 
-	// If the CodeQL flag is set, filter the language data to include only CodeQL-supported languages.
-	if codeql_flag {
-		languageData = IsCodeQLLanguage(languageData)
-	}
+	// The languages were filtered per repository, so only the top N remain to be selected.
+	languageData = pipeline.SelectTop(languageData)
 
 	// Render the language data as a table with percentages.
 	pterm.DefaultTable.WithHasHeader(true).WithData(func() [][]string {
 		rows := [][]string{{"Language", unit, "Percentage"}}
+		if byExtractor {
+			rows[0][0] = "Extractor"
+		}
 
 		// Sort the languages again for display purposes.
 		sortedLanguages := make([]struct {
@@ -187,17 +228,7 @@ func runData(cmd *cobra.Command, args []string) error {
 		}
 
 		for lang, bytes := range languageData {
-			var value float64
-			switch unit {
-			case "bytes":
-				value = float64(bytes)
-			case "kilobytes":
-				value = float64(bytes) / 1024
-			case "megabytes":
-				value = float64(bytes) / 1024 / 1024
-			case "gigabytes":
-				value = float64(bytes) / 1024 / 1024 / 1024
-			}
+			value := ConvertBytes(bytes, unit)
 			sortedLanguages = append(sortedLanguages, struct {
 				Language string
 				Value    float64
@@ -217,9 +248,48 @@ func runData(cmd *cobra.Command, args []string) error {
 		return rows
 	}()).Render()
 
+	// Render the language × group matrix if --group-by is set.
+	if !groupContext.IsEmpty() {
+		RenderGroupMatrix(fmt.Sprintf("Language %s by %s", unit, strings.Join(groupBy, " / ")), groupedData, topLanguageNames(languageData, "", 0), func(bytes int) string {
+			return fmt.Sprintf("%d", int(ConvertBytes(bytes, unit)))
+		}, func(part, total int) string {
+			return FormatPercentage(part, total, false)
+		})
+	}
+
+	// Render how many CodeQL extractors analyze each repository with --codeql-by extractor.
+	if byExtractor {
+		RenderExtractorHistogram(extractorHistogram, totalRepos)
+	}
+
+	return nil
+}
+
+// ValidateUnit checks that a --unit value is one of bytes, kilobytes, megabytes or gigabytes.
+func ValidateUnit(unit string) error {
+	if unit != "bytes" && unit != "kilobytes" && unit != "megabytes" && unit != "gigabytes" {
+		return fmt.Errorf("invalid unit specified. Options are: bytes, kilobytes, megabytes, gigabytes")
+	}
 	return nil
 }
 
+// ConvertBytes converts a number of bytes to the given unit (bytes, kilobytes, megabytes, gigabytes).
+func ConvertBytes(bytes int, unit string) float64 {
+	switch unit {
+	case "kilobytes":
+		return float64(bytes) / 1024
+	case "megabytes":
+		return float64(bytes) / 1024 / 1024
+	case "gigabytes":
+		return float64(bytes) / 1024 / 1024 / 1024
+	}
+	return float64(bytes)
+}
+
 func init() {
 	dataCmd.Flags().String("unit", "bytes", "Specify the unit for language data (bytes, kilobytes, megabytes, gigabytes)")
+	addGroupByFlag(dataCmd)
+	addThresholdFlags(dataCmd)
+	addSaveFlag(dataCmd)
+	addCodeQLByFlag(dataCmd)
 }
@@ -0,0 +1,306 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addThresholdFlags(diffCmd)
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <old> <new>",
+	Short: "Compare two runs saved with --save to show how programming languages changed between them",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		return runDiff(cmd, args)
+	},
+}
+
+// snapshotLanguages holds the per-language totals of a snapshot and the languages of each of its repositories.
+type snapshotLanguages struct {
+	Repos map[string]int
+	Bytes map[string]int
+	// ByRepo maps "org/repo" (lowercase) to the repository and its languages.
+	ByRepo map[string]snapshotRepository
+}
+
+// snapshotRepository is a repository of a snapshot with the languages that pass the filters.
+type snapshotRepository struct {
+	Repository
+	Kept map[string]struct{}
+}
+
+// repositoryKey identifies a repository across snapshots.
+func repositoryKey(repo Repository) string {
+	return strings.ToLower(repo.Org + "/" + repo.Name)
+}
+
+// unknownRepositories returns the keys of the repositories whose languages are unknown in either snapshot.
+func unknownRepositories(snapshots ...*Snapshot) map[string]struct{} {
+	unknown := make(map[string]struct{})
+	for _, snapshot := range snapshots {
+		for _, repo := range snapshot.Repositories {
+			if repo.LanguagesUnknown {
+				unknown[repositoryKey(repo)] = struct{}{}
+			}
+		}
+	}
+	return unknown
+}
+
+// summarizeSnapshot applies the language pipeline to a snapshot and totals it per language. Repositories whose
+// languages are unknown in either snapshot are kept, so that they are not reported as added or removed, but are
+// left out of the totals.
+func summarizeSnapshot(snapshot *Snapshot, pipeline *LanguagePipeline, unknown map[string]struct{}) snapshotLanguages {
+	summary := snapshotLanguages{
+		Repos:  make(map[string]int),
+		Bytes:  make(map[string]int),
+		ByRepo: make(map[string]snapshotRepository),
+	}
+	for _, repo := range snapshot.Repositories {
+		if _, ok := unknown[repositoryKey(repo)]; ok {
+			summary.ByRepo[repositoryKey(repo)] = snapshotRepository{Repository: repo, Kept: map[string]struct{}{}}
+			continue
+		}
+		kept := pipeline.RepositoryLanguages(repo)
+		for lang := range kept {
+			summary.Repos[lang]++
+			summary.Bytes[lang] += repo.LanguageSizes[lang]
+		}
+		summary.ByRepo[repositoryKey(repo)] = snapshotRepository{Repository: repo, Kept: kept}
+	}
+	return summary
+}
+
+// formatDelta formats a signed change (e.g., +3, -1 or 0).
+func formatDelta(delta int) string {
+	if delta > 0 {
+		return fmt.Sprintf("+%d", delta)
+	}
+	return fmt.Sprintf("%d", delta)
+}
+
+// formatSizeDelta formats a signed change in bytes (e.g., +1.2 MB).
+func formatSizeDelta(delta int) string {
+	switch {
+	case delta > 0:
+		return "+" + FormatSize(int64(delta))
+	case delta < 0:
+		return "-" + FormatSize(int64(-delta))
+	}
+	return "0 B"
+}
+
+// sortedLanguageList returns the languages of a set in alphabetical order, joined with commas.
+func sortedLanguageList(languages map[string]struct{}) string {
+	names := make([]string, 0, len(languages))
+	for lang := range languages {
+		names = append(names, lang)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// languageSetDifference returns the languages of a that are not in b.
+func languageSetDifference(a, b map[string]struct{}) map[string]struct{} {
+	difference := make(map[string]struct{})
+	for lang := range a {
+		if _, ok := b[lang]; !ok {
+			difference[lang] = struct{}{}
+		}
+	}
+	return difference
+}
+
+// renderRepositoryList displays a table of repositories with their primary language and languages.
+func renderRepositoryList(title string, keys []string, repos map[string]snapshotRepository) {
+	pterm.DefaultSection.Println(fmt.Sprintf("%s (%d)", title, len(keys)))
+	if len(keys) == 0 {
+		return
+	}
+	rows := [][]string{{"Organization", "Repository", "Primary Language", "Languages"}}
+	for _, key := range keys {
+		repo := repos[key]
+		rows = append(rows, []string{repo.Org, repo.Name, repo.PrimaryLanguage, sortedLanguageList(repo.Kept)})
+	}
+	pterm.DefaultTable.WithHasHeader(true).WithData(rows).Render()
+	pterm.Println()
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	// --top does not apply, since every language that changed is listed.
+	pipeline, err := LanguagePipelineWithoutTopFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	oldSnapshot, err := LoadSnapshot(args[0])
+	if err != nil {
+		return err
+	}
+	newSnapshot, err := LoadSnapshot(args[1])
+	if err != nil {
+		return err
+	}
+
+	// Sampled runs analyze different repositories, which would all show up as added or removed.
+	for i, snapshot := range []*Snapshot{oldSnapshot, newSnapshot} {
+		if snapshot.Sample != "" {
+			return fmt.Errorf("'%s' was saved from a sampled run (--sample %s, seed %d). Only complete runs can be compared", args[i], snapshot.Sample, snapshot.Seed)
+		}
+	}
+
+	// Print the runs being compared.
+	pterm.Info.Println(fmt.Sprintf("Old: %s (%s run on %s, %d repositories)", args[0], oldSnapshot.Command, formatDate(oldSnapshot.GeneratedAt), len(oldSnapshot.Repositories)))
+	pterm.Info.Println(fmt.Sprintf("New: %s (%s run on %s, %d repositories)", args[1], newSnapshot.Command, formatDate(newSnapshot.GeneratedAt), len(newSnapshot.Repositories)))
+	if oldSnapshot.Hostname != newSnapshot.Hostname {
+		pterm.Warning.Println(fmt.Sprintf("The runs target different hosts: %s and %s", oldSnapshot.Hostname, newSnapshot.Hostname))
+	}
+	// The data command takes the largest language as the primary language, while count and trend use the one
+	// reported by GitHub, so primary languages are only compared between runs that determine them the same way.
+	comparePrimary := oldSnapshot.Command == newSnapshot.Command || (oldSnapshot.Command != "data" && newSnapshot.Command != "data")
+	if !comparePrimary {
+		pterm.Warning.Println(fmt.Sprintf("The runs were saved by different commands (%s and %s), which determine primary languages differently. Primary language changes are not compared", oldSnapshot.Command, newSnapshot.Command))
+	}
+	unknown := unknownRepositories(oldSnapshot, newSnapshot)
+	if len(unknown) > 0 {
+		pterm.Info.Println(fmt.Sprintf("Repositories whose languages could not be fetched (not compared): %d", len(unknown)))
+	}
+	// Print the language filters and threshold so that results remain comparable across runs.
+	pterm.Info.Println(pipeline.String())
+	if thresholdInfo := GetThresholdInfo(min_bytes_flag, min_share_flag); thresholdInfo != "" {
+		pterm.Info.Println(thresholdInfo)
+	}
+	pterm.Println() // Add a new line
+
+	oldSummary := summarizeSnapshot(oldSnapshot, pipeline, unknown)
+	newSummary := summarizeSnapshot(newSnapshot, pipeline, unknown)
+	pipeline.WarnUnknownTypes()
+
+	// ── Section 1: Language Changes ─────────────────────────────────
+	// Every language in either run, sorted by the largest change in repos, then bytes.
+	allLanguages := make(map[string]struct{})
+	for lang := range oldSummary.Repos {
+		allLanguages[lang] = struct{}{}
+	}
+	for lang := range newSummary.Repos {
+		allLanguages[lang] = struct{}{}
+	}
+	sortedLanguages := make([]string, 0, len(allLanguages))
+	for lang := range allLanguages {
+		sortedLanguages = append(sortedLanguages, lang)
+	}
+	abs := func(v int) int {
+		if v < 0 {
+			return -v
+		}
+		return v
+	}
+	sort.Slice(sortedLanguages, func(i, j int) bool {
+		a, b := sortedLanguages[i], sortedLanguages[j]
+		deltaA, deltaB := abs(newSummary.Repos[a]-oldSummary.Repos[a]), abs(newSummary.Repos[b]-oldSummary.Repos[b])
+		if deltaA != deltaB {
+			return deltaA > deltaB
+		}
+		bytesA, bytesB := abs(newSummary.Bytes[a]-oldSummary.Bytes[a]), abs(newSummary.Bytes[b]-oldSummary.Bytes[b])
+		if bytesA != bytesB {
+			return bytesA > bytesB
+		}
+		return a < b
+	})
+
+	var appeared, disappeared []string
+	pterm.DefaultSection.Println("Language Changes")
+	rows := [][]string{{"Language", "Repos (Old)", "Repos (New)", "Repos Change", "Bytes (Old)", "Bytes (New)", "Bytes Change", "Status"}}
+	for _, lang := range sortedLanguages {
+		oldRepos, newRepos := oldSummary.Repos[lang], newSummary.Repos[lang]
+		status := ""
+		switch {
+		case oldRepos == 0:
+			status = pterm.Green("appeared")
+			appeared = append(appeared, lang)
+		case newRepos == 0:
+			status = pterm.Red("disappeared")
+			disappeared = append(disappeared, lang)
+		}
+		rows = append(rows, []string{
+			lang,
+			fmt.Sprintf("%d", oldRepos),
+			fmt.Sprintf("%d", newRepos),
+			formatDelta(newRepos - oldRepos),
+			FormatSize(int64(oldSummary.Bytes[lang])),
+			FormatSize(int64(newSummary.Bytes[lang])),
+			formatSizeDelta(newSummary.Bytes[lang] - oldSummary.Bytes[lang]),
+			status,
+		})
+	}
+	pterm.DefaultTable.WithHasHeader(true).WithData(rows).Render()
+	pterm.Println()
+	if len(appeared) > 0 {
+		sort.Strings(appeared)
+		pterm.Info.Println(fmt.Sprintf("Languages that appeared: %s", strings.Join(appeared, ", ")))
+	}
+	if len(disappeared) > 0 {
+		sort.Strings(disappeared)
+		pterm.Info.Println(fmt.Sprintf("Languages that disappeared: %s", strings.Join(disappeared, ", ")))
+	}
+	pterm.Println()
+
+	// ── Section 2: Added and Removed Repositories ───────────────────
+	var added, removed, changed []string
+	for key := range newSummary.ByRepo {
+		if _, ok := oldSummary.ByRepo[key]; !ok {
+			added = append(added, key)
+		}
+	}
+	for key, oldRepo := range oldSummary.ByRepo {
+		newRepo, ok := newSummary.ByRepo[key]
+		if !ok {
+			removed = append(removed, key)
+			continue
+		}
+		if _, ok := unknown[key]; ok {
+			continue
+		}
+		primaryChanged := comparePrimary && oldRepo.PrimaryLanguage != newRepo.PrimaryLanguage
+		if primaryChanged || len(languageSetDifference(oldRepo.Kept, newRepo.Kept)) > 0 || len(languageSetDifference(newRepo.Kept, oldRepo.Kept)) > 0 {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(changed)
+
+	renderRepositoryList("Repositories Added", added, newSummary.ByRepo)
+	renderRepositoryList("Repositories Removed", removed, oldSummary.ByRepo)
+
+	// ── Section 3: Repositories with Changed Languages ──────────────
+	pterm.DefaultSection.Println(fmt.Sprintf("Repositories with Changed Languages (%d)", len(changed)))
+	if len(changed) == 0 {
+		return nil
+	}
+	changedRows := [][]string{{"Organization", "Repository", "Primary Language", "Languages Added", "Languages Removed"}}
+	for _, key := range changed {
+		oldRepo, newRepo := oldSummary.ByRepo[key], newSummary.ByRepo[key]
+		primary := newRepo.PrimaryLanguage
+		if comparePrimary && oldRepo.PrimaryLanguage != newRepo.PrimaryLanguage {
+			primary = fmt.Sprintf("%s → %s", oldRepo.PrimaryLanguage, newRepo.PrimaryLanguage)
+		}
+		changedRows = append(changedRows, []string{
+			newRepo.Org,
+			newRepo.Name,
+			primary,
+			sortedLanguageList(languageSetDifference(newRepo.Kept, oldRepo.Kept)),
+			sortedLanguageList(languageSetDifference(oldRepo.Kept, newRepo.Kept)),
+		})
+	}
+	pterm.DefaultTable.WithHasHeader(true).WithData(changedRows).Render()
+
+	return nil
+}
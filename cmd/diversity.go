@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// MAX_HISTOGRAM_BUCKET groups repositories with this many languages or more into a single histogram bucket.
+const MAX_HISTOGRAM_BUCKET = 10
+
+// COVERAGE_THRESHOLD is the share of code used to report how many languages cover most of the code.
+const COVERAGE_THRESHOLD = 0.8
+
+func init() {
+	addThresholdFlags(diversityCmd)
+}
+
+var diversityCmd = &cobra.Command{
+	Use:   "diversity",
+	Short: "Analyze the diversity and concentration of programming languages used in repos across an enterprise or organization",
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		return runDiversity(cmd, args)
+	},
+}
+
+// diversityStats accumulates the language usage of a scope (an organization or the whole run).
+type diversityStats struct {
+	Scope string
+	Repos int
+	// Histogram counts repositories by number of languages, capped at MAX_HISTOGRAM_BUCKET.
+	Histogram     []int
+	TotalLangs    int
+	LanguageBytes map[string]int
+}
+
+func newDiversityStats(scope string) *diversityStats {
+	return &diversityStats{
+		Scope:         scope,
+		Histogram:     make([]int, MAX_HISTOGRAM_BUCKET+1),
+		LanguageBytes: make(map[string]int),
+	}
+}
+
+// add records the languages (and their bytes) of a single repository.
+func (d *diversityStats) add(languageSizes map[string]int) {
+	d.Repos++
+	d.TotalLangs += len(languageSizes)
+	bucket := len(languageSizes)
+	if bucket > MAX_HISTOGRAM_BUCKET {
+		bucket = MAX_HISTOGRAM_BUCKET
+	}
+	d.Histogram[bucket]++
+	for lang, bytes := range languageSizes {
+		d.LanguageBytes[lang] += bytes
+	}
+}
+
+// shares returns each language's share of the total bytes, sorted in descending order.
+func (d *diversityStats) shares() []float64 {
+	var total int
+	for _, bytes := range d.LanguageBytes {
+		total += bytes
+	}
+	shares := make([]float64, 0, len(d.LanguageBytes))
+	if total == 0 {
+		return shares
+	}
+	for _, bytes := range d.LanguageBytes {
+		shares = append(shares, float64(bytes)/float64(total))
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(shares)))
+	return shares
+}
+
+// shannonEntropy returns the Shannon entropy (in bits) of a distribution of shares.
+func shannonEntropy(shares []float64) float64 {
+	var entropy float64
+	for _, p := range shares {
+		if p > 0 {
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
+
+// herfindahlIndex returns the Herfindahl-Hirschman concentration index (sum of squared shares, 0-1) of a distribution.
+func herfindahlIndex(shares []float64) float64 {
+	var hhi float64
+	for _, p := range shares {
+		hhi += p * p
+	}
+	return hhi
+}
+
+// giniCoefficient returns the Gini coefficient (0 = perfectly even, 1 = fully concentrated) of a set of values.
+func giniCoefficient(values []float64) float64 {
+	n := len(values)
+	if n == 0 {
+		return 0
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	var weighted, total float64
+	for i, v := range sorted {
+		weighted += float64(i+1) * v
+		total += v
+	}
+	if total == 0 {
+		return 0
+	}
+	return 2*weighted/(float64(n)*total) - float64(n+1)/float64(n)
+}
+
+// languagesForCoverage returns how many languages (largest first) are needed to cover the given share of code.
+func languagesForCoverage(shares []float64, coverage float64) int {
+	var cumulative float64
+	for i, p := range shares {
+		cumulative += p
+		// Allow for floating point error when the shares sum to exactly the coverage.
+		if cumulative >= coverage-1e-9 {
+			return i + 1
+		}
+	}
+	return len(shares)
+}
+
+func runDiversity(cmd *cobra.Command, args []string) error {
+	org := org_flag
+	enterprise := enterprise_flag
+	repoLimit := repo_limit_flag
+	orgLimit := org_limit_flag
+	hostname := github_enterprise_server_url_flag
+
+	if err := ValidateFlags(org, enterprise); err != nil {
+		return err
+	}
+
+	// --top does not apply, since diversity covers every language.
+	pipeline, err := LanguagePipelineWithoutTopFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	order, err := ParseRepositoryOrder(order_by_flag, order_flag)
+	if err != nil {
+		return err
+	}
+
+	sizeFilter, err := NewSizeFilter(min_size_flag, max_size_flag)
+	if err != nil {
+		return err
+	}
+
+	orgFilter, err := ParseOrgFilter(org_include_flag, org_exclude_flag, orgs_file_flag)
+	if err != nil {
+		return err
+	}
+
+	// Describe the language filters in the order they are applied.
+	languageFilter := pipeline.String()
+	if !sizeFilter.IsEmpty() {
+		languageFilter += ", " + sizeFilter.String()
+	}
+	if !order.IsEmpty() {
+		languageFilter += ", " + order.String()
+	}
+	orgs, err := ResolveOrganizations(enterprise, org, orgLimit, repoLimit, languageFilter, orgFilter, hostname)
+	if err != nil {
+		return err
+	}
+
+	// Track statistics per organization and for the whole run.
+	overallScope := "All organizations"
+	if enterprise != "" {
+		overallScope = fmt.Sprintf("Enterprise: %s", enterprise)
+	}
+	overall := newDiversityStats(overallScope)
+	var perOrg []*diversityStats
+
+	// Iterate over each organization to fetch repositories and analyze languages.
+	for orgIndex, org := range orgs {
+		// Count and fetch the repositories of the organization with their languages.
+		repos, err := IndexOrganizationRepositories(org, orgIndex, len(orgs), repoLimit, order, hostname)
+		if err != nil {
+			return err
+		}
+
+		// Drop repositories outside the --min-size and --max-size range.
+		repos = sizeFilter.Apply(repos)
+
+		orgStats := newDiversityStats(org)
+		for _, repo := range repos {
+			// Keep the languages that meet the threshold and pass the language filters.
+			languageSizes := pipeline.RepositorySizes(repo)
+			orgStats.add(languageSizes)
+			overall.add(languageSizes)
+		}
+		perOrg = append(perOrg, orgStats)
+	}
+
+	// Print the total number of repositories analyzed.
+	pterm.Println() // Add a new line
+	pterm.Info.Println(fmt.Sprintf("Total number of repositories analyzed: %d", overall.Repos))
+	PrintSizeFilterSummary(sizeFilter)
+	// Print the language threshold so that results remain comparable across runs.
+	if thresholdInfo := GetThresholdInfo(min_bytes_flag, min_share_flag); thresholdInfo != "" {
+		pterm.Info.Println(thresholdInfo)
+	}
+	pipeline.WarnUnknownTypes()
+	pterm.Println() // Add a new line
+
+	// The overall scope is only worth a separate row when several organizations were analyzed.
+	scopes := perOrg
+	if len(perOrg) > 1 {
+		scopes = append(scopes, overall)
+	}
+
+	// ── Section 1: Diversity and Concentration Metrics ──────────────
+	pterm.DefaultSection.Println("Language Diversity and Concentration")
+	rows := [][]string{{"Scope", "Repos", "Languages", "Avg Languages/Repo", "Entropy (bits)", "Herfindahl", "Gini", fmt.Sprintf("Languages for %d%% of Code", int(COVERAGE_THRESHOLD*100))}}
+	for _, stats := range scopes {
+		shares := stats.shares()
+		avgLangs := 0.0
+		if stats.Repos > 0 {
+			avgLangs = float64(stats.TotalLangs) / float64(stats.Repos)
+		}
+		rows = append(rows, []string{
+			stats.Scope,
+			fmt.Sprintf("%d", stats.Repos),
+			fmt.Sprintf("%d", len(stats.LanguageBytes)),
+			fmt.Sprintf("%.2f", avgLangs),
+			fmt.Sprintf("%.2f", shannonEntropy(shares)),
+			fmt.Sprintf("%.3f", herfindahlIndex(shares)),
+			fmt.Sprintf("%.3f", giniCoefficient(shares)),
+			fmt.Sprintf("%d", languagesForCoverage(shares, COVERAGE_THRESHOLD)),
+		})
+	}
+	pterm.DefaultTable.WithHasHeader(true).WithData(rows).Render()
+	pterm.Println()
+
+	// ── Section 2: Languages per Repo Histogram ─────────────────────
+	pterm.DefaultSection.Println("Languages per Repo")
+	header := []string{"Languages"}
+	for _, stats := range scopes {
+		header = append(header, stats.Scope)
+	}
+	histogram := [][]string{header}
+	for bucket := 0; bucket <= MAX_HISTOGRAM_BUCKET; bucket++ {
+		label := fmt.Sprintf("%d", bucket)
+		if bucket == MAX_HISTOGRAM_BUCKET {
+			label = fmt.Sprintf("%d+", bucket)
+		}
+		row := []string{label}
+		for _, stats := range scopes {
+			row = append(row, fmt.Sprintf("%d (%s)", stats.Histogram[bucket], FormatPercentage(stats.Histogram[bucket], stats.Repos, false)))
+		}
+		histogram = append(histogram, row)
+	}
+	pterm.DefaultTable.WithHasHeader(true).WithData(histogram).Render()
+
+	return nil
+}
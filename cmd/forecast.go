@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"math"
+	"time"
+)
+
+// TREND_PARTIAL_MODES lists how trend --partial handles the current, incomplete period when forecasting.
+var TREND_PARTIAL_MODES = []string{"exclude", "annualize"}
+
+// tQuantiles975 holds the 97.5th percentile of Student's t distribution for 1 to 30 degrees of freedom,
+// used for 95% prediction intervals. Larger samples use the normal quantile.
+var tQuantiles975 = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// tQuantile975 returns the two-sided 95% critical value of Student's t distribution.
+func tQuantile975(df int) float64 {
+	if df < 1 {
+		return math.NaN()
+	}
+	if df <= len(tQuantiles975) {
+		return tQuantiles975[df-1]
+	}
+	return 1.96
+}
+
+// forecastPoint is a forecast value with its 95% prediction interval.
+type forecastPoint struct {
+	Value float64
+	Low   float64
+	High  float64
+}
+
+// linearFit is an ordinary least squares fit of y = Intercept + Slope*x.
+type linearFit struct {
+	Slope     float64
+	Intercept float64
+	// ResidualSE is the standard error of the residuals, used for prediction intervals.
+	ResidualSE float64
+	N          int
+	MeanX      float64
+	Sxx        float64
+}
+
+// fitLinear fits a line to the points whose y value is not NaN. It returns false if fewer than
+// three points are available, since a prediction interval needs at least one degree of freedom.
+func fitLinear(ys []float64) (linearFit, bool) {
+	var xs, vs []float64
+	for x, y := range ys {
+		if !math.IsNaN(y) {
+			xs = append(xs, float64(x))
+			vs = append(vs, y)
+		}
+	}
+	n := len(xs)
+	if n < 3 {
+		return linearFit{}, false
+	}
+
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += vs[i]
+	}
+	meanX, meanY := sumX/float64(n), sumY/float64(n)
+
+	var sxx, sxy float64
+	for i := range xs {
+		sxx += (xs[i] - meanX) * (xs[i] - meanX)
+		sxy += (xs[i] - meanX) * (vs[i] - meanY)
+	}
+	slope := sxy / sxx
+	intercept := meanY - slope*meanX
+
+	var sse float64
+	for i := range xs {
+		residual := vs[i] - (intercept + slope*xs[i])
+		sse += residual * residual
+	}
+
+	return linearFit{
+		Slope:      slope,
+		Intercept:  intercept,
+		ResidualSE: math.Sqrt(sse / float64(n-2)),
+		N:          n,
+		MeanX:      meanX,
+		Sxx:        sxx,
+	}, true
+}
+
+// predict returns the fitted value at x with its 95% prediction interval, clamped to the given bounds.
+func (f linearFit) predict(x float64, min, max float64) forecastPoint {
+	value := f.Intercept + f.Slope*x
+	margin := tQuantile975(f.N-2) * f.ResidualSE * math.Sqrt(1+1/float64(f.N)+(x-f.MeanX)*(x-f.MeanX)/f.Sxx)
+	clamp := func(v float64) float64 {
+		return math.Min(math.Max(v, min), max)
+	}
+	return forecastPoint{Value: clamp(value), Low: clamp(value - margin), High: clamp(value + margin)}
+}
+
+// forecastSeries fits a line to a series and forecasts the given number of periods after its last point.
+// Values are clamped to [min, max], e.g. counts cannot be negative and shares cannot exceed 100%.
+func forecastSeries(series []float64, periods int, min, max float64) ([]forecastPoint, bool) {
+	fit, ok := fitLinear(series)
+	if !ok {
+		return nil, false
+	}
+	points := make([]forecastPoint, periods)
+	for i := range points {
+		points[i] = fit.predict(float64(len(series)+i), min, max)
+	}
+	return points, true
+}
+
+// elapsedFraction returns the fraction of a period that has elapsed at the given time, between 0 and 1.
+func elapsedFraction(period int, interval string, now time.Time) float64 {
+	start := periodStart(period, interval)
+	end := periodStart(period+1, interval)
+	fraction := float64(now.Sub(start)) / float64(end.Sub(start))
+	return math.Min(math.Max(fraction, 0), 1)
+}
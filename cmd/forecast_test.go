@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"math"
+	"testing"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-4
+}
+
+func TestTQuantile975(t *testing.T) {
+	tests := []struct {
+		df   int
+		want float64
+	}{
+		{1, 12.706},
+		{2, 4.303},
+		{30, 2.042},
+		{31, 1.96},
+		{1000, 1.96},
+	}
+	for _, tt := range tests {
+		if got := tQuantile975(tt.df); got != tt.want {
+			t.Errorf("tQuantile975(%d) = %v, want %v", tt.df, got, tt.want)
+		}
+	}
+	if got := tQuantile975(0); !math.IsNaN(got) {
+		t.Errorf("tQuantile975(0) = %v, want NaN", got)
+	}
+}
+
+func TestFitLinear(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name         string
+		ys           []float64
+		ok           bool
+		slope        float64
+		intercept    float64
+		residualSE   float64
+		n            int
+		meanX, sumXX float64
+	}{
+		{"exact line", []float64{1, 3, 5, 7}, true, 2, 1, 0, 4, 1.5, 5},
+		{"skips NaN", []float64{1, nan, 5, 7}, true, 2, 1, 0, 3, 5.0 / 3, 14.0 / 3},
+		{"constant", []float64{5, 5, 5, 5}, true, 0, 5, 0, 4, 1.5, 5},
+		{"noisy", []float64{1, 2, 2, 3}, true, 0.6, 1.1, math.Sqrt(0.1), 4, 1.5, 5},
+		{"too few points", []float64{1, 2}, false, 0, 0, 0, 0, 0, 0},
+		{"too few after NaN", []float64{1, nan, nan, 3}, false, 0, 0, 0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		fit, ok := fitLinear(tt.ys)
+		if ok != tt.ok {
+			t.Errorf("%s: ok = %v, want %v", tt.name, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if !almostEqual(fit.Slope, tt.slope) || !almostEqual(fit.Intercept, tt.intercept) || !almostEqual(fit.ResidualSE, tt.residualSE) ||
+			fit.N != tt.n || !almostEqual(fit.MeanX, tt.meanX) || !almostEqual(fit.Sxx, tt.sumXX) {
+			t.Errorf("%s: fitLinear(%v) = %+v", tt.name, tt.ys, fit)
+		}
+	}
+}
+
+func TestForecastSeries(t *testing.T) {
+	tests := []struct {
+		name     string
+		series   []float64
+		min, max float64
+		want     forecastPoint
+	}{
+		// Residual SE sqrt(0.1), t(2) = 4.303 and sqrt(1 + 1/4 + 2.5²/5) = sqrt(2.5) give a margin of 2.1515.
+		{"prediction interval", []float64{1, 2, 2, 3}, 0, 100, forecastPoint{3.5, 3.5 - 2.1515, 3.5 + 2.1515}},
+		// One degree of freedom: residual SE sqrt(1/6), t(1) = 12.706 and sqrt(1 + 1/3 + 2²/2).
+		{"one degree of freedom", []float64{0, 1, 3}, -100, 100, forecastPoint{13.0 / 3, 13.0/3 - 9.470493, 13.0/3 + 9.470493}},
+		{"constant", []float64{5, 5, 5, 5}, 0, 100, forecastPoint{5, 5, 5}},
+		{"clamped at min", []float64{10, 5, 0}, 0, 100, forecastPoint{0, 0, 0}},
+		{"clamped at max", []float64{80, 90, 100}, 0, 100, forecastPoint{100, 100, 100}},
+	}
+	for _, tt := range tests {
+		points, ok := forecastSeries(tt.series, 1, tt.min, tt.max)
+		if !ok || len(points) != 1 {
+			t.Errorf("%s: forecastSeries(%v) = %v, %v", tt.name, tt.series, points, ok)
+			continue
+		}
+		got := points[0]
+		if !almostEqual(got.Value, tt.want.Value) || !almostEqual(got.Low, tt.want.Low) || !almostEqual(got.High, tt.want.High) {
+			t.Errorf("%s: forecastSeries(%v) = %+v, want %+v", tt.name, tt.series, got, tt.want)
+		}
+	}
+
+	// Each forecast period extends the line, with a wider interval further from the data.
+	points, ok := forecastSeries([]float64{1, 2, 2, 3}, 3, 0, 100)
+	if !ok || len(points) != 3 {
+		t.Fatalf("forecastSeries returned %v, %v", points, ok)
+	}
+	for i := 1; i < len(points); i++ {
+		if !almostEqual(points[i].Value-points[i-1].Value, 0.6) || points[i].High-points[i].Low <= points[i-1].High-points[i-1].Low {
+			t.Errorf("forecast %d = %+v does not extend %+v", i, points[i], points[i-1])
+		}
+	}
+
+	if _, ok := forecastSeries([]float64{1, 2}, 1, 0, 100); ok {
+		t.Error("forecastSeries with two points should not forecast")
+	}
+}
@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// GROUP_BY_DIMENSIONS lists the dimensions supported by --group-by, in addition to property:<name>.
+var GROUP_BY_DIMENSIONS = []string{"org", "visibility", "topic", "owning-team", "archived", "host"}
+
+// NO_GROUP_VALUE is the group reported for repositories without a value for a dimension (e.g., no topics).
+const NO_GROUP_VALUE = "(none)"
+
+// addGroupByFlag registers the --group-by flag on a command.
+func addGroupByFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&group_by_flag, "group-by", "", "A comma-separated list of dimensions to pivot results by: org, visibility, topic, owning-team, archived, host, property:<name> (nested in order)")
+}
+
+// ParseGroupBy splits a comma-separated list of --group-by dimensions and validates each entry.
+func ParseGroupBy(groupBy string) ([]string, error) {
+	dimensions := ParseLanguages(groupBy)
+	for _, dimension := range dimensions {
+		if strings.HasPrefix(dimension, "property:") {
+			if strings.TrimPrefix(dimension, "property:") == "" {
+				return nil, fmt.Errorf("invalid group-by dimension '%s'. Use property:<name> for repository custom properties", dimension)
+			}
+			continue
+		}
+		if !MatchesLanguageFilter(dimension, GROUP_BY_DIMENSIONS) {
+			return nil, fmt.Errorf("invalid group-by dimension '%s'. Options are: %s, property:<name>", dimension, strings.Join(GROUP_BY_DIMENSIONS, ", "))
+		}
+	}
+	return dimensions, nil
+}
+
+// GroupContext holds the per-organization data needed to group repositories that is not part of the repository itself.
+type GroupContext struct {
+	Dimensions []string
+	Hostname   string
+	// OwningTeams maps "org/repo" to the slugs of the teams with admin access to the repository.
+	OwningTeams map[string][]string
+	// Properties maps "org/repo" to its custom property values by property name.
+	Properties map[string]map[string][]string
+}
+
+// NewGroupContext creates a grouping context for the given dimensions.
+func NewGroupContext(dimensions []string, hostname string) *GroupContext {
+	return &GroupContext{
+		Dimensions:  dimensions,
+		Hostname:    hostname,
+		OwningTeams: make(map[string][]string),
+		Properties:  make(map[string]map[string][]string),
+	}
+}
+
+// IsEmpty reports whether no --group-by dimensions are set.
+func (c *GroupContext) IsEmpty() bool {
+	return len(c.Dimensions) == 0
+}
+
+// LoadOrganization fetches the owning teams and custom property values of an organization, if the dimensions require them.
+// Each is fetched once per organization, however many dimensions use it: all property:<name> dimensions share the
+// values of a single request.
+func (c *GroupContext) LoadOrganization(client *api.RESTClient, org string) error {
+	needsTeams, needsProperties := false, false
+	for _, dimension := range c.Dimensions {
+		switch {
+		case dimension == "owning-team":
+			needsTeams = true
+		case strings.HasPrefix(dimension, "property:"):
+			needsProperties = true
+		}
+	}
+
+	if needsTeams {
+		if err := c.loadOwningTeams(client, org); err != nil {
+			return fmt.Errorf("failed to fetch teams for organization '%s': %v", org, err)
+		}
+	}
+	if needsProperties {
+		if err := c.loadProperties(client, org); err != nil {
+			return fmt.Errorf("failed to fetch custom property values for organization '%s': %v", org, err)
+		}
+	}
+	return nil
+}
+
+// loadOwningTeams records the teams with admin access to each repository of an organization.
+func (c *GroupContext) loadOwningTeams(client *api.RESTClient, org string) error {
+	teams, err := fetchRESTPages[struct {
+		Slug string `json:"slug"`
+	}](client, fmt.Sprintf("orgs/%s/teams?per_page=100", org))
+	if err != nil {
+		return err
+	}
+
+	for _, team := range teams {
+		repos, err := fetchRESTPages[struct {
+			Name     string `json:"name"`
+			RoleName string `json:"role_name"`
+		}](client, fmt.Sprintf("orgs/%s/teams/%s/repos?per_page=100", org, team.Slug))
+		if err != nil {
+			return err
+		}
+		for _, repo := range repos {
+			if repo.RoleName == "admin" {
+				key := org + "/" + repo.Name
+				c.OwningTeams[key] = append(c.OwningTeams[key], team.Slug)
+			}
+		}
+	}
+	return nil
+}
+
+// loadProperties records the custom property values of each repository of an organization.
+func (c *GroupContext) loadProperties(client *api.RESTClient, org string) error {
+	values, err := fetchRESTPages[struct {
+		RepositoryName string `json:"repository_name"`
+		Properties     []struct {
+			PropertyName string      `json:"property_name"`
+			Value        interface{} `json:"value"`
+		} `json:"properties"`
+	}](client, fmt.Sprintf("orgs/%s/properties/values?per_page=100", org))
+	if err != nil {
+		return err
+	}
+
+	for _, repo := range values {
+		properties := make(map[string][]string)
+		for _, property := range repo.Properties {
+			// Multi-select properties are returned as arrays, all others as strings or null.
+			switch value := property.Value.(type) {
+			case string:
+				properties[property.PropertyName] = []string{value}
+			case []interface{}:
+				for _, v := range value {
+					properties[property.PropertyName] = append(properties[property.PropertyName], fmt.Sprint(v))
+				}
+			}
+		}
+		c.Properties[org+"/"+repo.RepositoryName] = properties
+	}
+	return nil
+}
+
+// dimensionValues returns the values of a single dimension for a repository.
+func (c *GroupContext) dimensionValues(repo Repository, dimension string) []string {
+	var values []string
+	switch {
+	case dimension == "org":
+		values = []string{repo.Org}
+	case dimension == "visibility":
+		values = []string{repo.Visibility}
+	case dimension == "topic":
+		values = repo.Topics
+	case dimension == "owning-team":
+		values = c.OwningTeams[repo.Org+"/"+repo.Name]
+	case dimension == "archived":
+		if repo.IsArchived {
+			values = []string{"archived"}
+		} else {
+			values = []string{"active"}
+		}
+	case dimension == "host":
+		values = []string{c.Hostname}
+	case strings.HasPrefix(dimension, "property:"):
+		values = c.Properties[repo.Org+"/"+repo.Name][strings.TrimPrefix(dimension, "property:")]
+	}
+	if len(values) == 0 || (len(values) == 1 && values[0] == "") {
+		return []string{NO_GROUP_VALUE}
+	}
+	return values
+}
+
+// GroupKeys returns the groups a repository belongs to. Nested dimensions are joined with " / ", and
+// multi-valued dimensions (topics, owning teams, multi-select properties) place the repository in several groups.
+func (c *GroupContext) GroupKeys(repo Repository) []string {
+	keys := []string{""}
+	for _, dimension := range c.Dimensions {
+		var nested []string
+		for _, key := range keys {
+			for _, value := range c.dimensionValues(repo, dimension) {
+				if key == "" {
+					nested = append(nested, value)
+				} else {
+					nested = append(nested, key+" / "+value)
+				}
+			}
+		}
+		keys = nested
+	}
+	return keys
+}
+
+// GroupedLanguageData accumulates language values (repository counts or bytes) per group.
+type GroupedLanguageData struct {
+	// Languages maps each group to its language values.
+	Languages map[string]map[string]int
+	// Totals holds the denominator of each group's percentages: repositories for counts, bytes for data.
+	Totals map[string]int
+}
+
+// NewGroupedLanguageData creates an empty grouped language data set.
+func NewGroupedLanguageData() *GroupedLanguageData {
+	return &GroupedLanguageData{
+		Languages: make(map[string]map[string]int),
+		Totals:    make(map[string]int),
+	}
+}
+
+// Add adds a value for a language in a group.
+func (g *GroupedLanguageData) Add(group, lang string, value int) {
+	if g.Languages[group] == nil {
+		g.Languages[group] = make(map[string]int)
+	}
+	g.Languages[group][lang] += value
+}
+
+// RenderGroupMatrix renders a language × group table. Rows are the given languages in order, and each cell
+// shows the formatted value with its formatted percentage of the group total.
+func RenderGroupMatrix(title string, grouped *GroupedLanguageData, languages []string, formatValue func(int) string, formatPercentage func(part, total int) string) {
+	groups := make([]string, 0, len(grouped.Totals))
+	for group := range grouped.Totals {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	pterm.Println()
+	pterm.DefaultSection.Println(title)
+
+	header := []string{"Language"}
+	for _, group := range groups {
+		header = append(header, group)
+	}
+	rows := [][]string{header}
+
+	for _, lang := range languages {
+		row := []string{lang}
+		for _, group := range groups {
+			value := grouped.Languages[group][lang]
+			row = append(row, fmt.Sprintf("%s (%s)", formatValue(value), formatPercentage(value, grouped.Totals[group])))
+		}
+		rows = append(rows, row)
+	}
+
+	totals := []string{"Total"}
+	for _, group := range groups {
+		totals = append(totals, formatValue(grouped.Totals[group]))
+	}
+	rows = append(rows, totals)
+
+	pterm.DefaultTable.WithHasHeader(true).WithData(rows).Render()
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed languages.yml
+var linguistCatalogYAML []byte
+
+// LINGUIST_LANGUAGE_TYPES lists the language types defined by GitHub Linguist.
+var LINGUIST_LANGUAGE_TYPES = []string{"programming", "markup", "data", "prose"}
+
+// UNKNOWN_LANGUAGE_TYPE is the --type of languages that are not in the embedded Linguist catalog.
+const UNKNOWN_LANGUAGE_TYPE = "unknown"
+
+var linguistCatalog map[string]string
+var linguistCatalogOnce sync.Once
+
+// GetLinguistCatalog returns the embedded Linguist catalog as a map of language name to language type.
+func GetLinguistCatalog() map[string]string {
+	linguistCatalogOnce.Do(func() {
+		var entries map[string]struct {
+			Type string `yaml:"type"`
+		}
+		if err := yaml.Unmarshal(linguistCatalogYAML, &entries); err != nil {
+			panic(fmt.Sprintf("invalid embedded Linguist catalog: %v", err))
+		}
+		linguistCatalog = make(map[string]string, len(entries))
+		for lang, entry := range entries {
+			linguistCatalog[lang] = entry.Type
+		}
+	})
+	return linguistCatalog
+}
+
+// LanguageType returns the Linguist type of a language, or an empty string if the language is unknown.
+func LanguageType(lang string) string {
+	return GetLinguistCatalog()[lang]
+}
+
+// ParseLanguageTypes splits a comma-separated list of Linguist types and validates each entry. The unknown type
+// selects the languages that are not in the embedded catalog.
+func ParseLanguageTypes(types string) ([]string, error) {
+	parsed := ParseLanguages(types)
+	for _, t := range parsed {
+		if !MatchesLanguageFilter(t, LINGUIST_LANGUAGE_TYPES) && !strings.EqualFold(t, UNKNOWN_LANGUAGE_TYPE) {
+			return nil, fmt.Errorf("invalid language type '%s'. Options are: %s, %s", t, strings.Join(LINGUIST_LANGUAGE_TYPES, ", "), UNKNOWN_LANGUAGE_TYPE)
+		}
+	}
+	return parsed, nil
+}
+
+// IsExcludedLanguage reports whether a language is removed by the exclusion list or falls outside the requested types.
+// Languages that are not in the embedded catalog only pass the type filter if it includes the unknown type.
+func IsExcludedLanguage(lang string, excluded []string, types []string) bool {
+	if MatchesLanguageFilter(lang, excluded) {
+		return true
+	}
+	// The "None" row of --primary mode is not a language, so it is never filtered by type.
+	if len(types) > 0 && lang != NO_PRIMARY_LANGUAGE && !MatchesLanguageFilter(languageTypeOrUnknown(lang), types) {
+		return true
+	}
+	return false
+}
+
+// languageTypeOrUnknown returns the Linguist type of a language, or the unknown type if it is not in the catalog.
+func languageTypeOrUnknown(lang string) string {
+	if languageType := LanguageType(lang); languageType != "" {
+		return languageType
+	}
+	return UNKNOWN_LANGUAGE_TYPE
+}
+
+// GetExclusionFilter describes the language exclusion and type filters, or returns an empty string if none are set.
+func GetExclusionFilter(excludeLanguage string, languageType string) string {
+	var parts []string
+	if excludeLanguage != "" {
+		parts = append(parts, fmt.Sprintf("Excluded languages: %s", excludeLanguage))
+	}
+	if languageType != "" {
+		parts = append(parts, fmt.Sprintf("Language types: %s", languageType))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return ", " + strings.Join(parts, ", ")
+}
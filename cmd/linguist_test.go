@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLanguagePipelineUnknownTypes(t *testing.T) {
+	pipeline := &LanguagePipeline{Types: []string{"programming"}, Excluded: []string{"Excluded Lang"}}
+	for _, lang := range []string{"Go", "Markdown", "Not A Linguist Language", "Excluded Lang", NO_PRIMARY_LANGUAGE} {
+		pipeline.Keep(lang)
+	}
+	// Only languages dropped because their type is unknown are reported, not those excluded by name or by a known type.
+	want := map[string]struct{}{"Not A Linguist Language": {}}
+	if !reflect.DeepEqual(pipeline.unknownTypes, want) {
+		t.Errorf("unknownTypes = %v, want %v", pipeline.unknownTypes, want)
+	}
+
+	pipeline = &LanguagePipeline{Types: []string{"programming", UNKNOWN_LANGUAGE_TYPE}}
+	if !pipeline.Keep("Not A Linguist Language") || pipeline.Keep("Markdown") || len(pipeline.unknownTypes) != 0 {
+		t.Errorf("--type programming,unknown should keep unknown languages and drop known non-programming ones")
+	}
+
+	if _, err := ParseLanguageTypes("programming,Unknown"); err != nil {
+		t.Errorf("ParseLanguageTypes should accept the unknown type: %v", err)
+	}
+}
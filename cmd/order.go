@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// REPOSITORY_ORDER_FIELDS lists the fields supported by --order-by.
+var REPOSITORY_ORDER_FIELDS = []string{"created", "pushed", "updated", "name", "stars"}
+
+// REPOSITORY_ORDER_DIRECTIONS lists the directions supported by --order.
+var REPOSITORY_ORDER_DIRECTIONS = []string{"asc", "desc"}
+
+// RepositoryOrder is the order in which repositories are fetched, so that limited scans return a
+// predictable set of repositories. An empty order keeps the API's default order.
+type RepositoryOrder struct {
+	Field     string
+	Direction string
+}
+
+// ParseRepositoryOrder validates the --order-by and --order flags. The direction defaults to descending
+// (newest or most starred first), except for names which default to ascending.
+func ParseRepositoryOrder(orderBy, order string) (RepositoryOrder, error) {
+	orderBy = strings.ToLower(strings.TrimSpace(orderBy))
+	order = strings.ToLower(strings.TrimSpace(order))
+	if orderBy == "" {
+		if order != "" {
+			return RepositoryOrder{}, fmt.Errorf("--order requires the --order-by flag")
+		}
+		return RepositoryOrder{}, nil
+	}
+	if !MatchesLanguageFilter(orderBy, REPOSITORY_ORDER_FIELDS) {
+		return RepositoryOrder{}, fmt.Errorf("invalid order field specified. Options are: %s", strings.Join(REPOSITORY_ORDER_FIELDS, ", "))
+	}
+	if order == "" {
+		order = "desc"
+		if orderBy == "name" {
+			order = "asc"
+		}
+	}
+	if !MatchesLanguageFilter(order, REPOSITORY_ORDER_DIRECTIONS) {
+		return RepositoryOrder{}, fmt.Errorf("invalid order direction specified. Options are: %s", strings.Join(REPOSITORY_ORDER_DIRECTIONS, ", "))
+	}
+	return RepositoryOrder{Field: orderBy, Direction: order}, nil
+}
+
+// IsEmpty reports whether no --order-by is set.
+func (o RepositoryOrder) IsEmpty() bool {
+	return o.Field == ""
+}
+
+// GraphQLArgument returns the orderBy argument for the GraphQL repositories connection, including its
+// leading comma, or an empty string to keep the default order.
+func (o RepositoryOrder) GraphQLArgument() string {
+	if o.IsEmpty() {
+		return ""
+	}
+	fields := map[string]string{
+		"created": "CREATED_AT",
+		"pushed":  "PUSHED_AT",
+		"updated": "UPDATED_AT",
+		"name":    "NAME",
+		"stars":   "STARGAZERS",
+	}
+	return fmt.Sprintf(", orderBy: {field: %s, direction: %s}", fields[o.Field], strings.ToUpper(o.Direction))
+}
+
+// RESTParameters returns the sort and direction query parameters for the REST repositories endpoint,
+// including their leading ampersand. The REST API cannot sort repositories by stars.
+func (o RepositoryOrder) RESTParameters() (string, error) {
+	if o.IsEmpty() {
+		return "", nil
+	}
+	sorts := map[string]string{
+		"created": "created",
+		"pushed":  "pushed",
+		"updated": "updated",
+		"name":    "full_name",
+	}
+	sort, ok := sorts[o.Field]
+	if !ok {
+		return "", fmt.Errorf("--order-by %s is not supported by the REST API used by this command. Options are: created, pushed, updated, name", o.Field)
+	}
+	return fmt.Sprintf("&sort=%s&direction=%s", sort, o.Direction), nil
+}
+
+// String describes the order for the run header.
+func (o RepositoryOrder) String() string {
+	return fmt.Sprintf("Order: %s %s", o.Field, o.Direction)
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// OrgFilter selects which organizations of an enterprise are analyzed. Patterns are globs (e.g., sandbox-*)
+// or regular expressions wrapped in slashes (e.g., /^train-\d+$/), both matched case-insensitively like logins.
+type OrgFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// ParseOrgFilter builds an organization filter from comma-separated include and exclude patterns
+// and an optional file containing one include pattern per line.
+func ParseOrgFilter(include, exclude, orgsFile string) (OrgFilter, error) {
+	filter := OrgFilter{
+		Include: splitOrgPatterns(include),
+		Exclude: splitOrgPatterns(exclude),
+	}
+
+	if orgsFile != "" {
+		patterns, err := readOrgsFile(orgsFile)
+		if err != nil {
+			return OrgFilter{}, err
+		}
+		filter.Include = append(filter.Include, patterns...)
+	}
+
+	for _, pattern := range append(append([]string{}, filter.Include...), filter.Exclude...) {
+		if _, err := matchOrgPattern(pattern, ""); err != nil {
+			return OrgFilter{}, fmt.Errorf("invalid organization pattern '%s': %v", pattern, err)
+		}
+	}
+
+	return filter, nil
+}
+
+// splitOrgPatterns splits a comma-separated list of organization patterns. A /regex/ pattern may itself contain
+// commas (e.g., /^team-[a-z]{2,4}$/): it ends at the first slash followed by a comma or the end of the list.
+func splitOrgPatterns(value string) []string {
+	var patterns []string
+	for value = strings.TrimSpace(value); value != ""; {
+		end := len(value)
+		if comma := strings.Index(value, ","); comma >= 0 {
+			end = comma
+		}
+		if strings.HasPrefix(value, "/") {
+			for i := 1; i < len(value); i++ {
+				if value[i] == '/' && strings.HasPrefix(strings.TrimSpace(value[i+1:])+",", ",") {
+					end = i + 1
+					break
+				}
+			}
+		}
+		if pattern := strings.TrimSpace(value[:end]); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+		value = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value[end:]), ","))
+	}
+	return patterns
+}
+
+// readOrgsFile reads organization patterns from a file, ignoring blank lines and # comments.
+func readOrgsFile(orgsFile string) ([]string, error) {
+	file, err := os.Open(orgsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --orgs-file: %v", err)
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read --orgs-file: %v", err)
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("--orgs-file %s does not contain any organizations", orgsFile)
+	}
+	return patterns, nil
+}
+
+// matchOrgPattern reports whether an organization login matches a glob or /regex/ pattern, ignoring case.
+func matchOrgPattern(pattern, login string) (bool, error) {
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile("(?i)" + pattern[1:len(pattern)-1])
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(login), nil
+	}
+	return path.Match(strings.ToLower(pattern), strings.ToLower(login))
+}
+
+// IsEmpty reports whether the filter has no include or exclude patterns.
+func (f OrgFilter) IsEmpty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// Matches reports whether an organization login passes the filter. An organization must match at least
+// one include pattern (if any are set) and must not match any exclude pattern.
+func (f OrgFilter) Matches(login string) bool {
+	if len(f.Include) > 0 && !matchesAnyOrgPattern(f.Include, login) {
+		return false
+	}
+	return !matchesAnyOrgPattern(f.Exclude, login)
+}
+
+func matchesAnyOrgPattern(patterns []string, login string) bool {
+	for _, pattern := range patterns {
+		// Patterns are validated by ParseOrgFilter, so errors cannot occur here.
+		if matched, _ := matchOrgPattern(pattern, login); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// String describes the filter for the run header, or returns an empty string if the filter is empty.
+func (f OrgFilter) String() string {
+	var parts []string
+	if len(f.Include) > 0 {
+		parts = append(parts, fmt.Sprintf("Organization include: %s", strings.Join(f.Include, ", ")))
+	}
+	if len(f.Exclude) > 0 {
+		parts = append(parts, fmt.Sprintf("Organization exclude: %s", strings.Join(f.Exclude, ", ")))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitOrgPatterns(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", nil},
+		{"acme", []string{"acme"}},
+		{" sandbox-* , acme ,", []string{"sandbox-*", "acme"}},
+		{"/^team-[a-z]{2,4}$/", []string{"/^team-[a-z]{2,4}$/"}},
+		{"sandbox-*, /^team-[a-z]{2,4}$/ ,acme", []string{"sandbox-*", "/^team-[a-z]{2,4}$/", "acme"}},
+		{"/a/b/,c", []string{"/a/b/", "c"}},
+	}
+	for _, tt := range tests {
+		if got := splitOrgPatterns(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitOrgPatterns(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMatchOrgPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		login   string
+		want    bool
+	}{
+		{"acme", "ACME", true},
+		{"sandbox-*", "Sandbox-42", true},
+		{"sandbox-*", "prod", false},
+		{"/^team-[a-z]{2,4}$/", "Team-ABC", true},
+		{"/^team-[a-z]{2,4}$/", "team-abcde", false},
+	}
+	for _, tt := range tests {
+		got, err := matchOrgPattern(tt.pattern, tt.login)
+		if err != nil {
+			t.Fatalf("matchOrgPattern(%q, %q): %v", tt.pattern, tt.login, err)
+		}
+		if got != tt.want {
+			t.Errorf("matchOrgPattern(%q, %q) = %v, want %v", tt.pattern, tt.login, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TREND_INTERVALS lists the intervals supported by trend --interval.
+var TREND_INTERVALS = []string{"year", "quarter", "month"}
+
+// TREND_DATE_FIELDS lists the repository dates supported by trend --date-field.
+var TREND_DATE_FIELDS = []string{"created", "pushed", "updated", "archived"}
+
+var periodBoundRE = regexp.MustCompile(`^(\d{4})(?:-(?:[Qq]([1-4])|(\d{2})))?$`)
+
+// Periods are represented as consecutive integers so that the previous period is always p-1:
+// the year itself for yearly intervals, year*4+quarter-1 for quarters and year*12+month-1 for months.
+
+// periodsPerYear returns the number of periods in a year for an interval.
+func periodsPerYear(interval string) int {
+	switch interval {
+	case "quarter":
+		return 4
+	case "month":
+		return 12
+	}
+	return 1
+}
+
+// periodOf returns the period containing a point in time.
+func periodOf(t time.Time, interval string) int {
+	switch interval {
+	case "quarter":
+		return t.Year()*4 + (int(t.Month())-1)/3
+	case "month":
+		return t.Year()*12 + int(t.Month()) - 1
+	}
+	return t.Year()
+}
+
+// periodYear returns the year a period belongs to.
+func periodYear(period int, interval string) int {
+	return period / periodsPerYear(interval)
+}
+
+// yearPeriods returns the first and last period of a year.
+func yearPeriods(year int, interval string) (int, int) {
+	n := periodsPerYear(interval)
+	return year * n, year*n + n - 1
+}
+
+// periodStart returns the first instant of a period, in UTC.
+func periodStart(period int, interval string) time.Time {
+	year := periodYear(period, interval)
+	switch interval {
+	case "quarter":
+		return time.Date(year, time.Month(period%4*3+1), 1, 0, 0, 0, 0, time.UTC)
+	case "month":
+		return time.Date(year, time.Month(period%12+1), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// periodLabel formats a period for display (e.g., 2024, 2024-Q2 or 2024-05).
+func periodLabel(period int, interval string) string {
+	year := periodYear(period, interval)
+	switch interval {
+	case "quarter":
+		return fmt.Sprintf("%d-Q%d", year, period%4+1)
+	case "month":
+		return fmt.Sprintf("%d-%02d", year, period%12+1)
+	}
+	return fmt.Sprintf("%d", year)
+}
+
+// repositoryDate returns the repository timestamp selected by --date-field. It is empty for the archived
+// date of repositories that are not archived.
+func repositoryDate(repo Repository, dateField string) string {
+	switch dateField {
+	case "pushed":
+		return repo.PushedAt
+	case "updated":
+		return repo.UpdatedAt
+	case "archived":
+		return repo.ArchivedAt
+	}
+	return repo.CreatedAt
+}
+
+// dateFieldName describes a --date-field value for titles (e.g., Last Pushed).
+func dateFieldName(dateField string) string {
+	switch dateField {
+	case "pushed":
+		return "Last Pushed"
+	case "updated":
+		return "Last Updated"
+	case "archived":
+		return "Archived"
+	}
+	return "Created"
+}
+
+// intervalName returns the capitalized name of an interval (e.g., Quarter).
+func intervalName(interval string) string {
+	return strings.ToUpper(interval[:1]) + interval[1:]
+}
+
+// intervalChangeLabel returns the abbreviation used for period-over-period changes (YoY, QoQ or MoM).
+func intervalChangeLabel(interval string) string {
+	switch interval {
+	case "quarter":
+		return "QoQ"
+	case "month":
+		return "MoM"
+	}
+	return "YoY"
+}
+
+// parsePeriodBound parses a --since or --until bound given as YYYY, YYYY-Qn or YYYY-MM into a period of
+// the interval. Bounds coarser than the interval cover their whole range: a year used as an upper bound
+// resolves to its last quarter or month. Bounds finer than the interval resolve to the period containing them.
+func parsePeriodBound(bound string, interval string, upper bool) (int, error) {
+	matches := periodBoundRE.FindStringSubmatch(strings.TrimSpace(bound))
+	if matches == nil {
+		return 0, fmt.Errorf("invalid period '%s'. Use YYYY, YYYY-Qn or YYYY-MM", bound)
+	}
+	year, _ := strconv.Atoi(matches[1])
+
+	switch {
+	case matches[2] != "":
+		quarter, _ := strconv.Atoi(matches[2])
+		start := time.Date(year, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, time.UTC)
+		if upper {
+			return periodOf(start.AddDate(0, 2, 0), interval), nil
+		}
+		return periodOf(start, interval), nil
+	case matches[3] != "":
+		month, _ := strconv.Atoi(matches[3])
+		if month < 1 || month > 12 {
+			return 0, fmt.Errorf("invalid period '%s'. Month must be between 01 and 12", bound)
+		}
+		return periodOf(time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC), interval), nil
+	}
+
+	first, last := yearPeriods(year, interval)
+	if upper {
+		return last, nil
+	}
+	return first, nil
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodOf(t *testing.T) {
+	tests := []struct {
+		date     time.Time
+		interval string
+		want     string
+	}{
+		{time.Date(2024, time.May, 15, 0, 0, 0, 0, time.UTC), "year", "2024"},
+		{time.Date(2024, time.May, 15, 0, 0, 0, 0, time.UTC), "quarter", "2024-Q2"},
+		{time.Date(2024, time.May, 15, 0, 0, 0, 0, time.UTC), "month", "2024-05"},
+		{time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), "quarter", "2024-Q1"},
+		{time.Date(2024, time.December, 31, 23, 59, 59, 0, time.UTC), "quarter", "2024-Q4"},
+		{time.Date(2024, time.December, 31, 23, 59, 59, 0, time.UTC), "month", "2024-12"},
+	}
+	for _, tt := range tests {
+		period := periodOf(tt.date, tt.interval)
+		if got := periodLabel(period, tt.interval); got != tt.want {
+			t.Errorf("periodOf(%s, %s) = %s, want %s", tt.date.Format(time.DateOnly), tt.interval, got, tt.want)
+		}
+		// The period starts at or before the date, and the next period after it.
+		if periodStart(period, tt.interval).After(tt.date) || !periodStart(period+1, tt.interval).After(tt.date) {
+			t.Errorf("period %s does not contain %s", tt.want, tt.date)
+		}
+	}
+
+	// Consecutive periods are consecutive integers across years.
+	if got := periodOf(time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), "quarter") - periodOf(time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC), "quarter"); got != 1 {
+		t.Errorf("2025-Q1 is %d periods after 2024-Q4, want 1", got)
+	}
+	if got := periodOf(time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), "month") - periodOf(time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC), "month"); got != 1 {
+		t.Errorf("2025-01 is %d periods after 2024-12, want 1", got)
+	}
+}
+
+func TestParsePeriodBound(t *testing.T) {
+	tests := []struct {
+		bound    string
+		interval string
+		upper    bool
+		want     string
+	}{
+		{"2024", "year", false, "2024"},
+		{"2024", "quarter", false, "2024-Q1"},
+		{"2024", "quarter", true, "2024-Q4"},
+		{"2024", "month", false, "2024-01"},
+		{"2024", "month", true, "2024-12"},
+		{"2024-Q2", "quarter", false, "2024-Q2"},
+		{"2024-q2", "quarter", true, "2024-Q2"},
+		{"2024-Q2", "month", false, "2024-04"},
+		{"2024-Q2", "month", true, "2024-06"},
+		{"2024-Q2", "year", true, "2024"},
+		{"2024-05", "month", false, "2024-05"},
+		{"2024-05", "quarter", false, "2024-Q2"},
+		{"2024-05", "quarter", true, "2024-Q2"},
+		{"2024-05", "year", true, "2024"},
+		{" 2024-05 ", "month", true, "2024-05"},
+	}
+	for _, tt := range tests {
+		period, err := parsePeriodBound(tt.bound, tt.interval, tt.upper)
+		if err != nil {
+			t.Errorf("parsePeriodBound(%q, %s, %v) returned an error: %v", tt.bound, tt.interval, tt.upper, err)
+			continue
+		}
+		if got := periodLabel(period, tt.interval); got != tt.want {
+			t.Errorf("parsePeriodBound(%q, %s, %v) = %s, want %s", tt.bound, tt.interval, tt.upper, got, tt.want)
+		}
+	}
+
+	for _, bound := range []string{"", "24", "2024-Q5", "2024-Q0", "2024-00", "2024-13", "2024-5", "2024/05"} {
+		if _, err := parsePeriodBound(bound, "month", false); err == nil {
+			t.Errorf("parsePeriodBound(%q) should return an error", bound)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// LanguagePipeline applies the language flags in a fixed order, so that they combine freely and give the
+// same results in every command:
+//
+//	scope → language filters → presets → exclusions → thresholds → top-N
+//
+// The scope (organizations, size filters, sampling) selects the repositories. The language filter, preset,
+// exclusion and threshold steps then apply to the languages of each repository before they are aggregated,
+// and the top N languages are selected last from the aggregated results, so that --top always returns N
+// languages when there are enough of them.
+type LanguagePipeline struct {
+	Languages []string
+	Preset    *Preset
+	Excluded  []string
+	Types     []string
+	MinBytes  int
+	MinShare  float64
+	Primary   bool
+	Top       int
+
+	// The flag values, to describe the pipeline in the run header.
+	language        string
+	excludeLanguage string
+	languageType    string
+
+	// The languages dropped by --type only because they are not in the embedded Linguist catalog.
+	unknownTypes map[string]struct{}
+}
+
+// LanguagePipelineFromFlags creates the language pipeline for the language flags of a command. Without an
+// explicit --top, all languages that pass a --language filter or preset are kept rather than the default top 10.
+func LanguagePipelineFromFlags(cmd *cobra.Command) (*LanguagePipeline, error) {
+	types, err := ParseLanguageTypes(type_flag)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateThreshold(min_bytes_flag, min_share_flag); err != nil {
+		return nil, err
+	}
+	if top_flag < 0 {
+		return nil, fmt.Errorf("--top cannot be negative")
+	}
+
+	top := top_flag
+	if (language_flag != "" || activePreset != nil) && !cmd.Flags().Changed("top") {
+		top = 0
+	}
+	return &LanguagePipeline{
+		Languages:       ParseLanguages(language_flag),
+		Preset:          activePreset,
+		Excluded:        ParseLanguages(exclude_language_flag),
+		Types:           types,
+		MinBytes:        min_bytes_flag,
+		MinShare:        min_share_flag,
+		Primary:         primary_flag,
+		Top:             top,
+		language:        language_flag,
+		excludeLanguage: exclude_language_flag,
+		languageType:    type_flag,
+	}, nil
+}
+
+// LanguagePipelineWithoutTopFromFlags creates the language pipeline of a command that covers every language that
+// passes the filters, such as diversity or diff, where --top does not apply.
+func LanguagePipelineWithoutTopFromFlags(cmd *cobra.Command) (*LanguagePipeline, error) {
+	pipeline, err := LanguagePipelineFromFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+	pipeline.Top = 0
+	return pipeline, nil
+}
+
+// CodeQLLanguagePipelineFromFlags creates the language pipeline of a command that always analyzes CodeQL-supported
+// languages, such as codeql-coverage: the codeql preset applies even without --codeql, and --top does not apply.
+func CodeQLLanguagePipelineFromFlags(cmd *cobra.Command) (*LanguagePipeline, error) {
+	if activePreset != nil && !activePreset.IsCodeQL() {
+		return nil, fmt.Errorf("the %s command always analyzes CodeQL-supported languages and cannot be used with --preset %s", cmd.Name(), activePreset.Name)
+	}
+	pipeline, err := LanguagePipelineWithoutTopFromFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+	pipeline.Preset = CodeQLPreset()
+	return pipeline, nil
+}
+
+// Keep reports whether a language passes the language filter, preset and exclusion steps. Languages dropped by
+// --type because their type is unknown are recorded, to be reported by WarnUnknownTypes.
+func (p *LanguagePipeline) Keep(lang string) bool {
+	if KeepLanguage(lang, p.Languages, p.Preset, p.Excluded, p.Types) {
+		return true
+	}
+	if len(p.Types) > 0 && LanguageType(lang) == "" && KeepLanguage(lang, p.Languages, p.Preset, p.Excluded, nil) {
+		if p.unknownTypes == nil {
+			p.unknownTypes = make(map[string]struct{})
+		}
+		p.unknownTypes[lang] = struct{}{}
+	}
+	return false
+}
+
+// WarnUnknownTypes reports the languages that --type dropped because they are not in the embedded Linguist
+// catalog, rather than leaving them silently misclassified.
+func (p *LanguagePipeline) WarnUnknownTypes() {
+	if len(p.unknownTypes) == 0 {
+		return
+	}
+	langs := make([]string, 0, len(p.unknownTypes))
+	for lang := range p.unknownTypes {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	pterm.Warning.Printf("Excluded %d languages that are not in the embedded Linguist catalog, so their type is unknown: %s. Add '%s' to --type to include them\n",
+		len(langs), strings.Join(langs, ", "), UNKNOWN_LANGUAGE_TYPE)
+}
+
+// RepositoryLanguages returns the languages of a repository that pass the filters and meet the thresholds, or
+// only its primary language with --primary.
+func (p *LanguagePipeline) RepositoryLanguages(repo Repository) map[string]struct{} {
+	languages := make(map[string]struct{})
+	for lang := range RepositoryLanguages(repo, p.Primary, p.MinBytes, p.MinShare) {
+		if p.Keep(lang) {
+			languages[lang] = struct{}{}
+		}
+	}
+	return languages
+}
+
+// RepositorySizes returns the size of each language of a repository that passes the filters and meets the thresholds.
+func (p *LanguagePipeline) RepositorySizes(repo Repository) map[string]int {
+	sizes := make(map[string]int)
+	for lang := range p.RepositoryLanguages(repo) {
+		sizes[lang] = repo.LanguageSizes[lang]
+	}
+	return sizes
+}
+
+// SelectTop keeps the top N languages of aggregated language data, or all of them if --top does not apply.
+func (p *LanguagePipeline) SelectTop(languageData map[string]int) map[string]int {
+	if p.Top <= 0 {
+		return languageData
+	}
+	topLanguages := make(map[string]int)
+	for _, lang := range topLanguageNames(languageData, "", p.Top) {
+		topLanguages[lang] = languageData[lang]
+	}
+	return topLanguages
+}
+
+// String describes the pipeline for the run header, in the order its steps are applied.
+func (p *LanguagePipeline) String() string {
+	var parts []string
+	if p.language != "" {
+		parts = append(parts, fmt.Sprintf("Language filter: %s", p.language))
+	}
+	if p.Preset != nil {
+		parts = append(parts, p.Preset.String())
+	}
+	if exclusionFilter := GetExclusionFilter(p.excludeLanguage, p.languageType); exclusionFilter != "" {
+		parts = append(parts, strings.TrimPrefix(exclusionFilter, ", "))
+	}
+	if p.Top > 0 {
+		parts = append(parts, fmt.Sprintf("Top languages limit: %d", p.Top))
+	}
+	if len(parts) == 0 {
+		return "All languages"
+	}
+	return strings.Join(parts, ", ")
+}
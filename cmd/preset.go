@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed presets.yml
+var presetsYAML []byte
+
+// CODEQL_PRESET is the built-in preset of CodeQL-supported languages, also selected by --codeql.
+const CODEQL_PRESET = "codeql"
+
+var preset_flag string
+var presets_file_flag string
+
+// activePreset is the preset selected by --preset or --codeql, or nil if none is set.
+var activePreset *Preset
+
+// Preset is a named set of languages supported by a tool, e.g. the languages Dependabot can update.
+type Preset struct {
+	Name      string   `yaml:"-"`
+	Title     string   `yaml:"title"`
+	Languages []string `yaml:"languages"`
+	languages map[string]struct{}
+}
+
+// addPresetFlags registers the --preset and --presets-file flags on a command and its subcommands.
+func addPresetFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&preset_flag, "preset", "", "Restrict analysis to the languages supported by a tool: codeql, dependabot, dependency-graph, copilot-autofix, or a preset from --presets-file")
+	cmd.PersistentFlags().StringVar(&presets_file_flag, "presets-file", "", "A YAML file of additional presets for --preset")
+}
+
+// LoadPresets returns the built-in presets, extended with the presets of a YAML file if one is given. Presets of
+// the file replace built-in presets of the same name, except for the codeql preset, which comes from the CodeQL catalog.
+func LoadPresets(presetsFile string) (map[string]*Preset, error) {
+	var presets map[string]*Preset
+	if err := yaml.Unmarshal(presetsYAML, &presets); err != nil {
+		return nil, fmt.Errorf("invalid embedded presets: %v", err)
+	}
+	if presetsFile != "" {
+		data, err := os.ReadFile(presetsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read presets: %v", err)
+		}
+		var userPresets map[string]*Preset
+		if err := yaml.Unmarshal(data, &userPresets); err != nil {
+			return nil, fmt.Errorf("failed to parse presets '%s': %v", presetsFile, err)
+		}
+		for name, preset := range userPresets {
+			if name == CODEQL_PRESET {
+				return nil, fmt.Errorf("the %s preset cannot be redefined in '%s'. Use --codeql-catalog to change the CodeQL languages", CODEQL_PRESET, presetsFile)
+			}
+			if preset == nil || len(preset.Languages) == 0 {
+				return nil, fmt.Errorf("preset '%s' in '%s' has no languages", name, presetsFile)
+			}
+			for _, lang := range preset.Languages {
+				if LanguageType(lang) == "" {
+					pterm.Warning.Printf("Preset '%s' lists '%s', which is not a known Linguist language\n", name, lang)
+				}
+			}
+			presets[name] = preset
+		}
+	}
+	for name, preset := range presets {
+		preset.Name = name
+		if preset.Title == "" {
+			preset.Title = name
+		}
+	}
+	return presets, nil
+}
+
+// GetPreset returns a preset by name. The languages of the codeql preset are those of the CodeQL version in use.
+func GetPreset(name, presetsFile string) (*Preset, error) {
+	presets, err := LoadPresets(presetsFile)
+	if err != nil {
+		return nil, err
+	}
+	preset, ok := presets[strings.TrimSpace(name)]
+	if !ok {
+		names := make([]string, 0, len(presets))
+		for name := range presets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("invalid preset '%s'. Options are: %s", name, strings.Join(names, ", "))
+	}
+	if preset.Name == CODEQL_PRESET {
+		preset.Languages = preset.Languages[:0]
+		for lang := range GetCodeQLLanguages() {
+			preset.Languages = append(preset.Languages, lang)
+		}
+		sort.Strings(preset.Languages)
+	}
+	preset.languages = make(map[string]struct{}, len(preset.Languages))
+	for _, lang := range preset.Languages {
+		preset.languages[lang] = struct{}{}
+	}
+	return preset, nil
+}
+
+// CodeQLPreset returns the codeql preset, for commands that always analyze CodeQL-supported languages.
+func CodeQLPreset() *Preset {
+	preset, err := GetPreset(CODEQL_PRESET, "")
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded presets: %v", err))
+	}
+	return preset
+}
+
+// IsCodeQL reports whether the preset is the codeql preset.
+func (p *Preset) IsCodeQL() bool {
+	return p != nil && p.Name == CODEQL_PRESET
+}
+
+// Has reports whether a language is supported by the preset.
+func (p *Preset) Has(lang string) bool {
+	_, ok := p.languages[lang]
+	return ok
+}
+
+// Matches reports whether a set of languages contains at least one language supported by the preset.
+func (p *Preset) Matches(languages map[string]struct{}) bool {
+	for lang := range languages {
+		if p.Has(lang) {
+			return true
+		}
+	}
+	return false
+}
+
+// String describes the preset for the run header.
+func (p *Preset) String() string {
+	if p.IsCodeQL() {
+		return "CodeQL language filter applied"
+	}
+	return fmt.Sprintf("Preset: %s (%s languages)", p.Name, p.Title)
+}
+
+// resolvePresetName returns the preset selected by --preset, or by its --codeql alias.
+func resolvePresetName() string {
+	if codeql_flag {
+		return CODEQL_PRESET
+	}
+	return strings.TrimSpace(preset_flag)
+}
+
+// resolvePresetForCommand sets the active preset before a command runs, once the CodeQL languages are resolved.
+func resolvePresetForCommand() error {
+	activePreset = nil
+	name := resolvePresetName()
+	if name == "" {
+		return nil
+	}
+	preset, err := GetPreset(name, presets_file_flag)
+	if err != nil {
+		return err
+	}
+	activePreset = preset
+	return nil
+}
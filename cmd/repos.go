@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// REPOS_SORT_FIELDS lists the fields supported by repos --sort.
+var REPOS_SORT_FIELDS = []string{"bytes", "share", "created", "pushed", "name", "org"}
+
+var sort_flag string
+
+func init() {
+	addThresholdFlags(reposCmd)
+	reposCmd.Flags().StringVar(&sort_flag, "sort", "bytes", "Sort repositories by: bytes, share, created, pushed (descending), or name, org (ascending)")
+}
+
+var reposCmd = &cobra.Command{
+	Use:   "repos",
+	Short: "List the repos that use one or more programming languages across an enterprise or organization",
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		return runRepos(cmd, args)
+	},
+}
+
+// languageRepository is a repository that uses one of the requested languages.
+type languageRepository struct {
+	Repository
+	Language string
+	Bytes    int
+	Share    float64
+}
+
+// formatDate formats a GitHub timestamp as a date, or returns it unchanged if it cannot be parsed.
+func formatDate(timestamp string) string {
+	parsed, err := time.Parse(GITHUB_TIMESTAMP_LAYOUT, timestamp)
+	if err != nil {
+		return timestamp
+	}
+	return parsed.Format("2006-01-02")
+}
+
+// sortLanguageRepositories sorts repositories by the given field. Sizes and dates sort descending, names ascending.
+func sortLanguageRepositories(repos []languageRepository, field string) {
+	sort.SliceStable(repos, func(i, j int) bool {
+		a, b := repos[i], repos[j]
+		switch field {
+		case "share":
+			return a.Share > b.Share
+		case "created":
+			return a.CreatedAt > b.CreatedAt
+		case "pushed":
+			return a.PushedAt > b.PushedAt
+		case "name":
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		case "org":
+			if !strings.EqualFold(a.Org, b.Org) {
+				return strings.ToLower(a.Org) < strings.ToLower(b.Org)
+			}
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		}
+		return a.Bytes > b.Bytes
+	})
+}
+
+func runRepos(cmd *cobra.Command, args []string) error {
+	org := org_flag
+	enterprise := enterprise_flag
+	repoLimit := repo_limit_flag
+	orgLimit := org_limit_flag
+	language := language_flag
+	hostname := github_enterprise_server_url_flag
+
+	if err := ValidateFlags(org, enterprise); err != nil {
+		return err
+	}
+
+	if len(ParseLanguages(language)) == 0 {
+		return fmt.Errorf("--language flag is required for the repos command")
+	}
+
+	if !MatchesLanguageFilter(sort_flag, REPOS_SORT_FIELDS) {
+		return fmt.Errorf("invalid sort field specified. Options are: %s", strings.Join(REPOS_SORT_FIELDS, ", "))
+	}
+
+	// --top does not apply, since every matching repository is listed.
+	pipeline, err := LanguagePipelineWithoutTopFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	order, err := ParseRepositoryOrder(order_by_flag, order_flag)
+	if err != nil {
+		return err
+	}
+
+	sizeFilter, err := NewSizeFilter(min_size_flag, max_size_flag)
+	if err != nil {
+		return err
+	}
+
+	orgFilter, err := ParseOrgFilter(org_include_flag, org_exclude_flag, orgs_file_flag)
+	if err != nil {
+		return err
+	}
+
+	// Describe the language filters in the order they are applied.
+	languageFilter := pipeline.String()
+	if !sizeFilter.IsEmpty() {
+		languageFilter += ", " + sizeFilter.String()
+	}
+	if !order.IsEmpty() {
+		languageFilter += ", " + order.String()
+	}
+	orgs, err := ResolveOrganizations(enterprise, org, orgLimit, repoLimit, languageFilter, orgFilter, hostname)
+	if err != nil {
+		return err
+	}
+
+	var matches []languageRepository
+	var totalRepos, matchedRepos int
+
+	// Iterate over each organization to fetch repositories and find the ones using the requested languages.
+	for orgIndex, org := range orgs {
+		// Count and fetch the repositories of the organization with their languages.
+		repos, err := IndexOrganizationRepositories(org, orgIndex, len(orgs), repoLimit, order, hostname)
+		if err != nil {
+			return err
+		}
+
+		// Drop repositories outside the --min-size and --max-size range.
+		repos = sizeFilter.Apply(repos)
+
+		// Increment the total repository count.
+		totalRepos += len(repos)
+
+		for _, repo := range repos {
+			// A repository using several of the requested languages is listed once per language, but counted once.
+			matched := false
+			for lang := range pipeline.RepositoryLanguages(repo) {
+				matched = true
+				share := 0.0
+				if repo.TotalSize > 0 {
+					share = float64(repo.LanguageSizes[lang]) / float64(repo.TotalSize) * 100
+				}
+				matches = append(matches, languageRepository{
+					Repository: repo,
+					Language:   lang,
+					Bytes:      repo.LanguageSizes[lang],
+					Share:      share,
+				})
+			}
+			if matched {
+				matchedRepos++
+			}
+		}
+	}
+
+	// Print the total number of repositories analyzed and matched.
+	pterm.Println() // Add a new line
+	pterm.Info.Println(fmt.Sprintf("Total number of repositories analyzed: %d", totalRepos))
+	PrintSizeFilterSummary(sizeFilter)
+	// Print the language threshold so that results remain comparable across runs.
+	if thresholdInfo := GetThresholdInfo(min_bytes_flag, min_share_flag); thresholdInfo != "" {
+		pterm.Info.Println(thresholdInfo)
+	}
+	pipeline.WarnUnknownTypes()
+	pterm.Info.Println(fmt.Sprintf("Repositories using %s: %d", language, matchedRepos))
+	if len(matches) > matchedRepos {
+		pterm.Info.Println(fmt.Sprintf("Repository-language matches listed: %d", len(matches)))
+	}
+	pterm.Println() // Add a new line
+
+	if len(matches) == 0 {
+		return nil
+	}
+
+	sortLanguageRepositories(matches, sort_flag)
+
+	// Only show the language column when more than one language was requested.
+	showLanguage := len(pipeline.Languages) > 1
+	header := []string{"Organization", "Repository"}
+	if showLanguage {
+		header = append(header, "Language")
+	}
+	header = append(header, "Created", "Last Push", "Bytes", "Share", "URL")
+	rows := [][]string{header}
+
+	for _, match := range matches {
+		row := []string{match.Org, match.Name}
+		if showLanguage {
+			row = append(row, match.Language)
+		}
+		row = append(row,
+			formatDate(match.CreatedAt),
+			formatDate(match.PushedAt),
+			FormatSize(int64(match.Bytes)),
+			fmt.Sprintf("%.1f%%", match.Share),
+			match.URL,
+		)
+		rows = append(rows, row)
+	}
+
+	pterm.DefaultTable.WithHasHeader(true).WithData(rows).Render()
+
+	return nil
+}
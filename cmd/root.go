@@ -10,10 +10,23 @@ import (
 var enterprise_flag string
 var org_flag string
 var org_limit_flag int
+var org_include_flag string
+var org_exclude_flag string
+var orgs_file_flag string
 var repo_limit_flag int
 var top_flag int
 var language_flag string
 var codeql_flag bool
+var exclude_language_flag string
+var type_flag string
+var min_size_flag string
+var max_size_flag string
+var min_bytes_flag int
+var min_share_flag float64
+var primary_flag bool
+var group_by_flag string
+var order_by_flag string
+var order_flag string
 var github_enterprise_server_url_flag string
 
 var RootCmd = &cobra.Command{
@@ -27,18 +40,44 @@ func _root() error {
 	RootCmd.PersistentFlags().StringVarP(&enterprise_flag, "enterprise", "e", "", "GitHub Enterprise slug (e.g., github)")
 	RootCmd.PersistentFlags().StringVarP(&org_flag, "org", "o", "", "Specify the organization")
 	RootCmd.PersistentFlags().IntVar(&org_limit_flag, "org-limit", 5, "The maximum number of organizations to analyze for an enterprise")
+	RootCmd.PersistentFlags().StringVar(&org_include_flag, "org-include", "", "A comma-separated list of organization globs or /regexes/ to include from an enterprise, matched case-insensitively (applied before --org-limit)")
+	RootCmd.PersistentFlags().StringVar(&org_exclude_flag, "org-exclude", "", "A comma-separated list of organization globs or /regexes/ to exclude from an enterprise, matched case-insensitively (applied before --org-limit)")
+	RootCmd.PersistentFlags().StringVar(&orgs_file_flag, "orgs-file", "", "A file of organization globs or /regexes/ to include from an enterprise, one per line")
 	RootCmd.PersistentFlags().IntVar(&repo_limit_flag, "repo-limit", 10, "The maximum number of repositories to analyze per organization")
-	RootCmd.PersistentFlags().IntVarP(&top_flag, "top", "t", 10, "Return the top N languages (mutually exclusive with --language, --codeql)")
-	RootCmd.PersistentFlags().StringVarP(&language_flag, "language", "l", "", "A comma-separated list of languages to filter on (case-sensitive, mutually exclusive with --codeql, --top)")
-	RootCmd.PersistentFlags().BoolVar(&codeql_flag, "codeql", false, "Restrict analysis to CodeQL-supported languages (mutually exclusive with --language, --top)")
+	RootCmd.PersistentFlags().IntVarP(&top_flag, "top", "t", 10, "Return the top N languages, selected after all other language filters (all languages by default with --language, --codeql, or --preset)")
+	RootCmd.PersistentFlags().StringVarP(&language_flag, "language", "l", "", "A comma-separated list of languages to filter on (case-sensitive)")
+	RootCmd.PersistentFlags().BoolVar(&codeql_flag, "codeql", false, "Restrict analysis to CodeQL-supported languages, same as --preset codeql")
+	addPresetFlags(RootCmd)
+	RootCmd.PersistentFlags().StringVar(&exclude_language_flag, "exclude-language", "", "A comma-separated list of languages to exclude (case-sensitive, applied before --top)")
+	RootCmd.PersistentFlags().StringVar(&type_flag, "type", "", "A comma-separated list of Linguist language types to include: programming, markup, data, prose, unknown (applied before --top)")
+	RootCmd.PersistentFlags().StringVar(&min_size_flag, "min-size", "", "Exclude repositories smaller than this size on disk (e.g., 50KB)")
+	RootCmd.PersistentFlags().StringVar(&max_size_flag, "max-size", "", "Exclude repositories larger than this size on disk (e.g., 2GB)")
+	RootCmd.PersistentFlags().StringVar(&order_by_flag, "order-by", "", "Fetch repositories in a fixed order so that --repo-limit scans are reproducible: created, pushed, updated, name, stars")
+	RootCmd.PersistentFlags().StringVar(&order_flag, "order", "", "The direction for --order-by: asc, desc (defaults to desc, or asc for name)")
+	addCodeQLCatalogFlags(RootCmd)
 	RootCmd.PersistentFlags().StringVarP(&github_enterprise_server_url_flag, "github-enterprise-server-url", "u", "github.com", "GitHub Enterprise Server URL (e.g., github.company.com)")
 
+	// Resolve the CodeQL languages before the preset, since the codeql preset depends on them.
+	RootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := resolveCodeQLSupportForCommand(cmd, args); err != nil {
+			return err
+		}
+		return resolvePresetForCommand()
+	}
+
 	RootCmd.MarkFlagsMutuallyExclusive("enterprise", "org")
-	RootCmd.MarkFlagsMutuallyExclusive("top", "language", "codeql")
+	RootCmd.MarkFlagsMutuallyExclusive("codeql", "preset")
 
 	RootCmd.AddCommand(countCmd)
 	RootCmd.AddCommand(trendCmd)
 	RootCmd.AddCommand(dataCmd)
+	RootCmd.AddCommand(cooccurrenceCmd)
+	RootCmd.AddCommand(reposCmd)
+	RootCmd.AddCommand(diversityCmd)
+	RootCmd.AddCommand(diffCmd)
+	RootCmd.AddCommand(compareCmd)
+	RootCmd.AddCommand(codeqlCoverageCmd)
+	RootCmd.AddCommand(codeqlPlanCmd)
 
 	return RootCmd.Execute()
 }
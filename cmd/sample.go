@@ -0,0 +1,326 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cli/go-gh/v2"
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// WILSON_Z is the normal quantile used for 95% confidence intervals.
+const WILSON_Z = 1.96
+
+var sample_flag string
+var seed_flag int64
+
+// addSampleFlags registers the --sample and --seed flags on a command.
+func addSampleFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&sample_flag, "sample", "", "Analyze a uniform random sample of the repositories across all organizations analyzed, as a number (e.g., 200) or a percentage (e.g., 10%) (mutually exclusive with --repo-limit; not available on data, whose shares of bytes have no confidence interval)")
+	cmd.Flags().Int64Var(&seed_flag, "seed", 0, "The random seed for --sample, to draw the same sample again (defaults to a random seed, printed with the results)")
+}
+
+// Sampler draws a simple random sample of the repositories of all organizations analyzed, so that the sampled
+// repositories can be pooled: every repository has the same chance of being sampled, whatever its organization.
+type Sampler struct {
+	// Size is the number of repositories to sample, or 0 when sampling a percentage.
+	Size    int
+	Percent float64
+	Seed    int64
+	// Population and Sampled count the repositories available and sampled across organizations.
+	Population int
+	Sampled    int
+	// populations and positions hold the number of repositories of each organization and the sampled positions
+	// among them, once the sample is planned.
+	populations map[string]int
+	positions   map[string][]int
+	rng         *rand.Rand
+}
+
+// NewSampler parses a --sample value. It returns nil if sampling is not requested. A random seed is
+// used unless one is given, so that the seed can be printed and the sample drawn again.
+func NewSampler(sample string, seed int64, seedSet bool) (*Sampler, error) {
+	sample = strings.TrimSpace(sample)
+	if sample == "" {
+		if seedSet {
+			return nil, fmt.Errorf("--seed requires the --sample flag")
+		}
+		return nil, nil
+	}
+
+	sampler := &Sampler{Seed: seed}
+	if !seedSet {
+		sampler.Seed = time.Now().UnixNano()
+	}
+	sampler.rng = rand.New(rand.NewSource(sampler.Seed))
+
+	if strings.HasSuffix(sample, "%") {
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(sample, "%"), 64)
+		if err != nil || percent <= 0 || percent > 100 {
+			return nil, fmt.Errorf("invalid sample '%s'. Use a number of repositories or a percentage between 0%% and 100%%", sample)
+		}
+		sampler.Percent = percent
+		return sampler, nil
+	}
+
+	size, err := strconv.Atoi(sample)
+	if err != nil || size <= 0 {
+		return nil, fmt.Errorf("invalid sample '%s'. Use a number of repositories or a percentage between 0%% and 100%%", sample)
+	}
+	sampler.Size = size
+	return sampler, nil
+}
+
+// SamplerFromFlags creates the sampler for the --sample and --seed flags of a command, or nil if not sampling.
+func SamplerFromFlags(cmd *cobra.Command) (*Sampler, error) {
+	if sample_flag != "" && cmd.Flags().Changed("repo-limit") {
+		return nil, fmt.Errorf("--sample and --repo-limit cannot be used together")
+	}
+	if sample_flag != "" && order_by_flag != "" {
+		return nil, fmt.Errorf("--sample and --order-by cannot be used together")
+	}
+	return NewSampler(sample_flag, seed_flag, cmd.Flags().Changed("seed"))
+}
+
+// SizeFor returns the number of repositories to sample from a population of the given number of repositories.
+func (s *Sampler) SizeFor(total int) int {
+	size := s.Size
+	if s.Percent > 0 {
+		size = int(math.Ceil(float64(total) * s.Percent / 100))
+	}
+	if size > total {
+		size = total
+	}
+	return size
+}
+
+// Plan counts the repositories of every organization and draws the positions to sample from all of them
+// at once, so that the sample is a simple random sample of the repositories across organizations.
+func (s *Sampler) Plan(orgs []string, hostname string) error {
+	s.populations = make(map[string]int, len(orgs))
+
+	progressBar, _ := pterm.DefaultProgressbar.WithTotal(len(orgs)).WithTitle("Counting repositories to sample").Start()
+	var total int
+	for _, org := range orgs {
+		count, err := CountRepositoriesGraphQL(org, hostname)
+		if err != nil {
+			progressBar.Stop()
+			pterm.Error.Printf("Failed to count repositories for organization '%s': %v\n", org, err)
+			return err
+		}
+		s.populations[org] = count
+		total += count
+		progressBar.Increment()
+	}
+	progressBar.Stop()
+
+	s.positions = assignPositions(orgs, s.populations, samplePositions(s.rng, total, s.SizeFor(total)))
+	return nil
+}
+
+// samplePositions draws size distinct positions out of total uniformly at random, in ascending order. It uses
+// Floyd's algorithm, so that memory grows with the sample rather than with the population.
+func samplePositions(rng *rand.Rand, total, size int) []int {
+	selected := make(map[int]struct{}, size)
+	for j := total - size; j < total; j++ {
+		position := rng.Intn(j + 1)
+		if _, ok := selected[position]; ok {
+			position = j
+		}
+		selected[position] = struct{}{}
+	}
+	positions := make([]int, 0, size)
+	for position := range selected {
+		positions = append(positions, position)
+	}
+	sort.Ints(positions)
+	return positions
+}
+
+// assignPositions maps ascending positions in the repositories of all organizations, taken in order, to
+// positions within each organization.
+func assignPositions(orgs []string, populations map[string]int, positions []int) map[string][]int {
+	assigned := make(map[string][]int, len(orgs))
+	offset := 0
+	for _, org := range orgs {
+		for len(positions) > 0 && positions[0] < offset+populations[org] {
+			assigned[org] = append(assigned[org], positions[0]-offset)
+			positions = positions[1:]
+		}
+		offset += populations[org]
+	}
+	return assigned
+}
+
+// String describes the sample for the run header.
+func (s *Sampler) String() string {
+	if s.Percent > 0 {
+		return fmt.Sprintf("Random sample: %g%% of repositories across organizations (seed %d)", s.Percent, s.Seed)
+	}
+	return fmt.Sprintf("Random sample: %d repositories across organizations (seed %d)", s.Size, s.Seed)
+}
+
+// SamplingFraction returns the share of the population that was sampled, between 0 and 1.
+func (s *Sampler) SamplingFraction() float64 {
+	if s.Population == 0 {
+		return 0
+	}
+	return float64(s.Sampled) / float64(s.Population)
+}
+
+// WilsonInterval returns the 95% Wilson score interval, in percent, of a proportion estimated from a simple random
+// sample drawn without replacement. The sampling fraction applies the finite population correction: the interval
+// narrows as the sample approaches the whole population, and is the proportion itself once every repository is sampled.
+func WilsonInterval(part, total int, fraction float64) (float64, float64) {
+	if total == 0 {
+		return 0, 0
+	}
+	p := float64(part) / float64(total)
+	if fraction >= 1 {
+		return p * 100, p * 100
+	}
+	// Sampling without replacement has the variance of a sample with replacement this many times larger.
+	n := float64(total) / (1 - math.Max(fraction, 0))
+	z2 := WILSON_Z * WILSON_Z
+	center := (p + z2/(2*n)) / (1 + z2/n)
+	margin := WILSON_Z * math.Sqrt(p*(1-p)/n+z2/(4*n*n)) / (1 + z2/n)
+	return math.Max(center-margin, 0) * 100, math.Min(center+margin, 1) * 100
+}
+
+// FormatPercentageCI formats a percentage estimated from a sample with its 95% confidence interval.
+func (s *Sampler) FormatPercentageCI(part, total int, precise bool) string {
+	low, high := WilsonInterval(part, total, s.SamplingFraction())
+	return fmt.Sprintf("%s (%.1f–%.1f%%)", FormatPercentage(part, total, precise), low, high)
+}
+
+// SampleOrganizationRepositories fetches the repositories of an organization drawn by Plan with their
+// languages. The sampled positions are resolved to repositories through the REST API, whose pages can be
+// requested by number, and then fetched by node ID with the GraphQL API.
+func SampleOrganizationRepositories(client *api.RESTClient, org string, orgIndex, orgCount int, sampler *Sampler, hostname string) ([]Repository, error) {
+	totalReposInOrg := sampler.populations[org]
+	if totalReposInOrg == 0 {
+		pterm.Warning.Println(fmt.Sprintf("No repositories found for organization %d of %d: %s", orgIndex+1, orgCount, org))
+		return nil, nil
+	}
+
+	positions := sampler.positions[org]
+	sampleSize := len(positions)
+	pterm.Success.Println(fmt.Sprintf("Sampling organization %d of %d: %s (%d of %d repositories)", orgIndex+1, orgCount, org, sampleSize, totalReposInOrg))
+	sampler.Population += totalReposInOrg
+	if sampleSize == 0 {
+		return nil, nil
+	}
+
+	// Group the sampled positions by REST page.
+	const perPage = 100
+	offsetsByPage := make(map[int][]int)
+	for _, position := range positions {
+		page := position/perPage + 1
+		offsetsByPage[page] = append(offsetsByPage[page], position%perPage)
+	}
+	pages := make([]int, 0, len(offsetsByPage))
+	for page := range offsetsByPage {
+		pages = append(pages, page)
+	}
+	sort.Ints(pages)
+
+	progressBar, _ := pterm.DefaultProgressbar.WithTotal(sampleSize).WithTitle("Fetching sampled repositories and their languages").Start()
+
+	// Resolve the sampled positions to node IDs. Repositories are sorted by name so that positions are stable.
+	var nodeIDs []string
+	for _, page := range pages {
+		repos, _, err := fetchRESTPage[struct {
+			NodeID string `json:"node_id"`
+		}](client, fmt.Sprintf("orgs/%s/repos?per_page=%d&page=%d&sort=full_name&direction=asc", org, perPage, page))
+		if err != nil {
+			progressBar.Stop()
+			pterm.Error.Printf("Failed to fetch repositories for organization '%s': %v\n", org, err)
+			return nil, err
+		}
+		for _, offset := range offsetsByPage[page] {
+			// The REST API can list slightly fewer repositories than the GraphQL count, e.g. while repositories are deleted.
+			if offset < len(repos) {
+				nodeIDs = append(nodeIDs, repos[offset].NodeID)
+			}
+		}
+	}
+
+	// Fetch the sampled repositories with their languages in batches.
+	var sampled []Repository
+	for start := 0; start < len(nodeIDs); start += perPage {
+		end := start + perPage
+		if end > len(nodeIDs) {
+			end = len(nodeIDs)
+		}
+		repos, err := FetchRepositoriesByID(org, nodeIDs[start:end], hostname)
+		if err != nil {
+			progressBar.Stop()
+			return nil, err
+		}
+		sampled = append(sampled, repos...)
+		progressBar.Add(end - start)
+	}
+	progressBar.Stop()
+
+	sampler.Sampled += len(sampled)
+	return sampled, nil
+}
+
+// LoadOrganizationRepositories fetches the repositories of an organization with their languages: a random
+// sample if a sampler is given, otherwise the first repositories in the given order up to the repository limit.
+func LoadOrganizationRepositories(client *api.RESTClient, org string, orgIndex, orgCount, repoLimit int, order RepositoryOrder, sampler *Sampler, hostname string) ([]Repository, error) {
+	if sampler != nil {
+		return SampleOrganizationRepositories(client, org, orgIndex, orgCount, sampler, hostname)
+	}
+	return IndexOrganizationRepositories(org, orgIndex, orgCount, repoLimit, order, hostname)
+}
+
+// FetchRepositoriesByID fetches repositories of an organization with their languages by GraphQL node ID (up to 100 at a time).
+func FetchRepositoriesByID(org string, nodeIDs []string, hostname string) ([]Repository, error) {
+	quoted := make([]string, len(nodeIDs))
+	for i, id := range nodeIDs {
+		quoted[i] = strconv.Quote(id)
+	}
+
+	query := fmt.Sprintf(`{
+		nodes(ids: [%s]) {
+			... on Repository {
+				%s
+			}
+		}
+	}`, strings.Join(quoted, ", "), RepositoryGraphQLFields())
+
+	response, stderr, err := gh.Exec("api", "graphql", "--hostname", hostname, "-f", "query="+query)
+	if err != nil {
+		pterm.Error.Printf("Failed to fetch sampled repositories for organization '%s': %v\n", org, err)
+		pterm.Error.Printf("gh CLI stderr: %s\n", stderr.String())
+		return nil, err
+	}
+
+	var result struct {
+		Data struct {
+			Nodes []*graphQLRepository `json:"nodes"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(response.Bytes(), &result); err != nil {
+		pterm.Error.Printf("Failed to parse sampled repositories for organization '%s': %v\n", org, err)
+		return nil, err
+	}
+
+	repos := make([]Repository, 0, len(result.Data.Nodes))
+	for _, node := range result.Data.Nodes {
+		// Repositories that can no longer be resolved are returned as null.
+		if node != nil {
+			repos = append(repos, node.toRepository(org))
+		}
+	}
+	return repos, nil
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestWilsonInterval(t *testing.T) {
+	tests := []struct {
+		name      string
+		part      int
+		total     int
+		fraction  float64
+		low, high float64
+	}{
+		{"half", 50, 100, 0, 40.382983, 59.617017},
+		{"none", 0, 10, 0, 0, 27.754017},
+		{"all", 10, 10, 0, 72.245983, 100},
+		// Sampling half of the population without replacement narrows the interval like a sample twice as large.
+		{"finite population correction", 50, 100, 0.5, 43.135962, 56.864038},
+		{"fully enumerated", 30, 100, 1, 30, 30},
+		{"empty", 0, 0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		low, high := WilsonInterval(tt.part, tt.total, tt.fraction)
+		if !almostEqual(low, tt.low) || !almostEqual(high, tt.high) {
+			t.Errorf("%s: WilsonInterval(%d, %d, %v) = %.6f–%.6f, want %.6f–%.6f", tt.name, tt.part, tt.total, tt.fraction, low, high, tt.low, tt.high)
+		}
+	}
+}
+
+func TestSamplerSizeFor(t *testing.T) {
+	tests := []struct {
+		sample string
+		total  int
+		want   int
+	}{
+		{"200", 1000, 200},
+		{"200", 50, 50},
+		{"10%", 1000, 100},
+		{"10%", 15, 2},
+		{"100%", 37, 37},
+	}
+	for _, tt := range tests {
+		sampler, err := NewSampler(tt.sample, 1, true)
+		if err != nil {
+			t.Fatalf("NewSampler(%q): %v", tt.sample, err)
+		}
+		if got := sampler.SizeFor(tt.total); got != tt.want {
+			t.Errorf("NewSampler(%q).SizeFor(%d) = %d, want %d", tt.sample, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestSamplePositions(t *testing.T) {
+	tests := []struct {
+		total, size int
+	}{
+		{10, 0},
+		{10, 3},
+		{10, 10},
+		{1000000, 5},
+	}
+	for _, tt := range tests {
+		positions := samplePositions(rand.New(rand.NewSource(42)), tt.total, tt.size)
+		if len(positions) != tt.size {
+			t.Errorf("samplePositions(%d, %d) returned %d positions", tt.total, tt.size, len(positions))
+		}
+		for i, position := range positions {
+			if position < 0 || position >= tt.total || (i > 0 && position <= positions[i-1]) {
+				t.Errorf("samplePositions(%d, %d) = %v, want distinct ascending positions below %d", tt.total, tt.size, positions, tt.total)
+				break
+			}
+		}
+	}
+
+	// The same seed draws the same sample.
+	a := samplePositions(rand.New(rand.NewSource(7)), 500, 20)
+	b := samplePositions(rand.New(rand.NewSource(7)), 500, 20)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("samplePositions with the same seed returned %v and %v", a, b)
+	}
+
+	// Every position is equally likely to be drawn.
+	counts := make([]int, 5)
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		for _, position := range samplePositions(rng, 5, 2) {
+			counts[position]++
+		}
+	}
+	for position, count := range counts {
+		// Each position is drawn with probability 2/5, i.e. about 4000 times.
+		if count < 3800 || count > 4200 {
+			t.Errorf("position %d was drawn %d times out of 10000, want about 4000", position, count)
+		}
+	}
+}
+
+func TestAssignPositions(t *testing.T) {
+	orgs := []string{"a", "b", "c", "d"}
+	populations := map[string]int{"a": 3, "b": 0, "c": 5, "d": 2}
+	got := assignPositions(orgs, populations, []int{0, 2, 3, 7, 8, 9})
+	want := map[string][]int{"a": {0, 2}, "c": {0, 4}, "d": {0, 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("assignPositions = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+)
+
+var sizeRE = regexp.MustCompile(`^(?i)\s*([0-9]+(?:\.[0-9]+)?)\s*(B|KB|MB|GB|TB)?\s*$`)
+
+var sizeUnits = map[string]int64{
+	"B":  1,
+	"KB": 1024,
+	"MB": 1024 * 1024,
+	"GB": 1024 * 1024 * 1024,
+	"TB": 1024 * 1024 * 1024 * 1024,
+}
+
+// sizeBuckets are the upper bounds (exclusive, in bytes) used to report the size distribution of excluded repositories.
+var sizeBuckets = []int64{
+	100 * sizeUnits["KB"],
+	sizeUnits["MB"],
+	10 * sizeUnits["MB"],
+	100 * sizeUnits["MB"],
+	sizeUnits["GB"],
+}
+
+// ParseSize parses a human-readable size such as 50KB or 2GB into bytes. Units are powers of 1024,
+// and a number without a unit is interpreted as bytes. An empty string parses as 0.
+func ParseSize(size string) (int64, error) {
+	if strings.TrimSpace(size) == "" {
+		return 0, nil
+	}
+	matches := sizeRE.FindStringSubmatch(size)
+	if matches == nil {
+		return 0, fmt.Errorf("invalid size '%s'. Use a number followed by an optional unit: B, KB, MB, GB, TB (e.g., 50KB)", size)
+	}
+	value, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size '%s': %v", size, err)
+	}
+	unit := strings.ToUpper(matches[2])
+	if unit == "" {
+		unit = "B"
+	}
+	return int64(value * float64(sizeUnits[unit])), nil
+}
+
+// FormatSize formats a number of bytes as a human-readable size.
+func FormatSize(bytes int64) string {
+	for _, unit := range []string{"TB", "GB", "MB", "KB"} {
+		if bytes >= sizeUnits[unit] {
+			return fmt.Sprintf("%.1f %s", float64(bytes)/float64(sizeUnits[unit]), unit)
+		}
+	}
+	return fmt.Sprintf("%d B", bytes)
+}
+
+// SizeFilter restricts repositories to a disk usage range and records the repositories it excludes.
+type SizeFilter struct {
+	Min int64
+	Max int64
+	// BelowMin and AboveMax count excluded repositories per size bucket (see sizeBuckets).
+	BelowMin []int
+	AboveMax []int
+}
+
+// NewSizeFilter parses the --min-size and --max-size flags into a size filter.
+func NewSizeFilter(minSize, maxSize string) (*SizeFilter, error) {
+	minBytes, err := ParseSize(minSize)
+	if err != nil {
+		return nil, fmt.Errorf("--min-size: %v", err)
+	}
+	maxBytes, err := ParseSize(maxSize)
+	if err != nil {
+		return nil, fmt.Errorf("--max-size: %v", err)
+	}
+	if maxBytes > 0 && minBytes > maxBytes {
+		return nil, fmt.Errorf("--min-size (%s) cannot be greater than --max-size (%s)", minSize, maxSize)
+	}
+	return &SizeFilter{
+		Min:      minBytes,
+		Max:      maxBytes,
+		BelowMin: make([]int, len(sizeBuckets)+1),
+		AboveMax: make([]int, len(sizeBuckets)+1),
+	}, nil
+}
+
+// IsEmpty reports whether neither a minimum nor a maximum size is set.
+func (f *SizeFilter) IsEmpty() bool {
+	return f.Min == 0 && f.Max == 0
+}
+
+// Allow reports whether a repository with the given disk usage (in kilobytes, as reported by GitHub)
+// is within the size range. Excluded repositories are recorded for the summary.
+func (f *SizeFilter) Allow(diskUsageKB int) bool {
+	size := int64(diskUsageKB) * sizeUnits["KB"]
+	switch {
+	case f.Min > 0 && size < f.Min:
+		f.BelowMin[sizeBucket(size)]++
+		return false
+	case f.Max > 0 && size > f.Max:
+		f.AboveMax[sizeBucket(size)]++
+		return false
+	}
+	return true
+}
+
+// Apply returns the repositories that are within the size range.
+func (f *SizeFilter) Apply(repos []Repository) []Repository {
+	if f.IsEmpty() {
+		return repos
+	}
+	filtered := make([]Repository, 0, len(repos))
+	for _, repo := range repos {
+		if f.Allow(repo.DiskUsage) {
+			filtered = append(filtered, repo)
+		}
+	}
+	return filtered
+}
+
+// Excluded returns the total number of repositories excluded by the filter.
+func (f *SizeFilter) Excluded() int {
+	total := 0
+	for i := range f.BelowMin {
+		total += f.BelowMin[i] + f.AboveMax[i]
+	}
+	return total
+}
+
+// String describes the size range for the run header.
+func (f *SizeFilter) String() string {
+	switch {
+	case f.Min > 0 && f.Max > 0:
+		return fmt.Sprintf("Repository size: %s to %s", FormatSize(f.Min), FormatSize(f.Max))
+	case f.Min > 0:
+		return fmt.Sprintf("Repository size: at least %s", FormatSize(f.Min))
+	case f.Max > 0:
+		return fmt.Sprintf("Repository size: at most %s", FormatSize(f.Max))
+	}
+	return ""
+}
+
+func sizeBucket(size int64) int {
+	for i, upper := range sizeBuckets {
+		if size < upper {
+			return i
+		}
+	}
+	return len(sizeBuckets)
+}
+
+func sizeBucketLabel(bucket int) string {
+	switch {
+	case bucket == 0:
+		return fmt.Sprintf("< %s", FormatSize(sizeBuckets[0]))
+	case bucket == len(sizeBuckets):
+		return fmt.Sprintf(">= %s", FormatSize(sizeBuckets[len(sizeBuckets)-1]))
+	}
+	return fmt.Sprintf("%s - %s", FormatSize(sizeBuckets[bucket-1]), FormatSize(sizeBuckets[bucket]))
+}
+
+// PrintSizeFilterSummary prints the size range and the size distribution of the repositories it excluded.
+func PrintSizeFilterSummary(f *SizeFilter) {
+	if f.IsEmpty() {
+		return
+	}
+	PrintInfoWithFormat("%s (%d repositories excluded)", f.String(), f.Excluded())
+	if f.Excluded() == 0 {
+		return
+	}
+
+	rows := [][]string{{"Excluded size", "Below --min-size", "Above --max-size"}}
+	for bucket := range f.BelowMin {
+		if f.BelowMin[bucket] == 0 && f.AboveMax[bucket] == 0 {
+			continue
+		}
+		rows = append(rows, []string{
+			sizeBucketLabel(bucket),
+			fmt.Sprintf("%d", f.BelowMin[bucket]),
+			fmt.Sprintf("%d", f.AboveMax[bucket]),
+		})
+	}
+	pterm.DefaultTable.WithHasHeader(true).WithData(rows).Render()
+}
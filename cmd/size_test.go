@@ -0,0 +1,37 @@
+package cmd
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		size string
+		want int64
+	}{
+		{"", 0},
+		{"  ", 0},
+		{"100", 100},
+		{"100B", 100},
+		{"50KB", 50 * 1024},
+		{"50kb", 50 * 1024},
+		{"1.5 MB", 1572864},
+		{" 2GB ", 2 * 1024 * 1024 * 1024},
+		{"1TB", 1024 * 1024 * 1024 * 1024},
+		{"0.5KB", 512},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.size)
+		if err != nil {
+			t.Errorf("ParseSize(%q) returned an error: %v", tt.size, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, want %d", tt.size, got, tt.want)
+		}
+	}
+
+	for _, size := range []string{"abc", "10PB", "-1KB", "1,5MB", "KB", "1 K B"} {
+		if _, err := ParseSize(size); err == nil {
+			t.Errorf("ParseSize(%q) should return an error", size)
+		}
+	}
+}
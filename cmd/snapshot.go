@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// SNAPSHOT_VERSION is the version of the file format written by --save.
+const SNAPSHOT_VERSION = 1
+
+var save_flag string
+
+// Snapshot is a saved run: the repositories that were analyzed with their languages, before any language
+// filters or thresholds, so that runs can later be compared with the diff command.
+type Snapshot struct {
+	Version int    `json:"version"`
+	Command string `json:"command"`
+	// Sample and Seed record the --sample and seed of a sampled run.
+	Sample        string       `json:"sample,omitempty"`
+	Seed          int64        `json:"seed,omitempty"`
+	GeneratedAt   string       `json:"generated_at"`
+	Hostname      string       `json:"hostname"`
+	Enterprise    string       `json:"enterprise,omitempty"`
+	Organizations []string     `json:"organizations"`
+	Repositories  []Repository `json:"repositories"`
+}
+
+// addSaveFlag registers the --save flag on a command.
+func addSaveFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&save_flag, "save", "", "Save the analyzed repositories and their languages to a JSON file, to compare runs with the diff command")
+}
+
+// SaveSnapshot writes the analyzed repositories to a snapshot file if --save is set, with the sample they
+// were drawn from, if any.
+func SaveSnapshot(command, hostname, enterprise string, orgs []string, repos []Repository, sampler *Sampler) error {
+	if save_flag == "" {
+		return nil
+	}
+
+	snapshot := Snapshot{
+		Version:       SNAPSHOT_VERSION,
+		Command:       command,
+		GeneratedAt:   time.Now().UTC().Format(GITHUB_TIMESTAMP_LAYOUT),
+		Hostname:      hostname,
+		Enterprise:    enterprise,
+		Organizations: orgs,
+		Repositories:  repos,
+	}
+	if sampler != nil {
+		snapshot.Sample = strings.TrimSpace(sample_flag)
+		snapshot.Seed = sampler.Seed
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %v", err)
+	}
+	if err := os.WriteFile(save_flag, data, 0644); err != nil {
+		return fmt.Errorf("failed to save snapshot: %v", err)
+	}
+
+	pterm.Info.Println(fmt.Sprintf("Saved %d repositories to %s", len(repos), save_flag))
+	return nil
+}
+
+// LoadSnapshot reads a snapshot file written by --save.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %v", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot '%s': %v", path, err)
+	}
+	if snapshot.Version != SNAPSHOT_VERSION {
+		return nil, fmt.Errorf("unsupported snapshot version %d in '%s'", snapshot.Version, path)
+	}
+	return &snapshot, nil
+}
+
+// WithLanguageSizes returns a copy of a repository with the given language sizes, as fetched by the data command.
+// The REST API does not return the primary language with the sizes, so the largest language is used, as GitHub does.
+func (r Repository) WithLanguageSizes(languageSizes map[string]int) Repository {
+	r.LanguageSizes = languageSizes
+	r.Languages = make(map[string]struct{}, len(languageSizes))
+	r.TotalSize = 0
+	r.PrimaryLanguage = ""
+	var largest int
+	for lang, bytes := range languageSizes {
+		r.Languages[lang] = struct{}{}
+		r.TotalSize += bytes
+		if bytes > largest || (bytes == largest && strings.Compare(lang, r.PrimaryLanguage) < 0) {
+			largest = bytes
+			r.PrimaryLanguage = lang
+		}
+	}
+	return r
+}
@@ -2,9 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"math"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/cli/go-gh/v2/pkg/api"
 	"github.com/guptarohit/asciigraph"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
@@ -15,12 +18,251 @@ const GITHUB_TIMESTAMP_LAYOUT = "2006-01-02T15:04:05Z"
 // MAX_GRAPH_SERIES limits the number of language series shown in the line graph for readability.
 const MAX_GRAPH_SERIES = 10
 
+// MAX_GRAPH_WIDTH limits the width of the line graph when there are many periods (e.g., monthly intervals).
+const MAX_GRAPH_WIDTH = 120
+
 var min_year_flag int
 var max_year_flag int
+var interval_flag string
+var since_flag string
+var until_flag string
+var date_field_flag string
+var metric_flag string
+var view_flag string
+var forecast_flag int
+var partial_flag string
 
 func init() {
 	trendCmd.Flags().IntVar(&min_year_flag, "min-year", 0, "Minimum year to include in the trend output")
 	trendCmd.Flags().IntVar(&max_year_flag, "max-year", time.Now().Year()-1, "Maximum year to include in the trend output (defaults to last year)")
+	trendCmd.Flags().StringVar(&interval_flag, "interval", "year", "Group repositories by: year, quarter, month")
+	trendCmd.Flags().StringVar(&since_flag, "since", "", "First period to include in the trend output, as YYYY, YYYY-Qn or YYYY-MM (mutually exclusive with --min-year)")
+	trendCmd.Flags().StringVar(&until_flag, "until", "", "Last period to include in the trend output, as YYYY, YYYY-Qn or YYYY-MM (defaults to the last complete period, mutually exclusive with --max-year)")
+	trendCmd.Flags().StringVar(&date_field_flag, "date-field", "created", "Repository date to group by: created, pushed, updated, archived")
+	trendCmd.Flags().StringVar(&metric_flag, "metric", "repos", "Measure each language by: repos (number of repos using it), bytes (bytes of code)")
+	trendCmd.Flags().String("unit", "bytes", "Specify the unit for --metric bytes (bytes, kilobytes, megabytes, gigabytes)")
+	trendCmd.Flags().StringVar(&view_flag, "view", "absolute", "Plot each language as: absolute (value per period), share (percentage of the period), cumulative (running total)")
+	trendCmd.Flags().IntVar(&forecast_flag, "forecast", 0, "The number of periods to forecast for each plotted language, using a linear fit with a 95% prediction interval")
+	trendCmd.Flags().StringVar(&partial_flag, "partial", "exclude", "How to handle the current, incomplete period when forecasting: exclude, annualize")
+	trendCmd.MarkFlagsMutuallyExclusive("since", "min-year")
+	trendCmd.MarkFlagsMutuallyExclusive("until", "max-year")
+	addThresholdFlags(trendCmd)
+	addPrimaryFlag(trendCmd)
+	addSaveFlag(trendCmd)
+	addBenchmarkFlags(trendCmd)
+	addSampleFlags(trendCmd)
+}
+
+// trendOptions holds the settings that control how trend periods are computed and rendered.
+type trendOptions struct {
+	Interval  string
+	DateField string
+	Metric    string
+	Unit      string
+	View      string
+	Forecast  int
+	Partial   string
+	Benchmark *Benchmark
+	Sampler   *Sampler
+	Top       int
+}
+
+// TREND_METRICS lists the metrics supported by trend --metric.
+var TREND_METRICS = []string{"repos", "bytes"}
+
+// TREND_VIEWS lists the views supported by trend --view.
+var TREND_VIEWS = []string{"absolute", "share", "cumulative"}
+
+// weight returns how much a language of a repository adds to the trend: one repo, or its bytes with --metric bytes.
+func (o trendOptions) weight(repo Repository, lang string) int {
+	if o.Metric == "bytes" {
+		return repo.LanguageSizes[lang]
+	}
+	return 1
+}
+
+// value converts a raw trend value (repos, or bytes with --metric bytes) to the displayed unit.
+func (o trendOptions) value(raw int) float64 {
+	if o.Metric == "bytes" {
+		return ConvertBytes(raw, o.Unit)
+	}
+	return float64(raw)
+}
+
+// precision returns the number of decimals used to display values.
+func (o trendOptions) precision() int {
+	if o.Metric == "bytes" && o.Unit != "bytes" {
+		return 2
+	}
+	return 0
+}
+
+// format formats a raw trend value in the displayed unit.
+func (o trendOptions) format(raw int) string {
+	return fmt.Sprintf("%.*f", o.precision(), o.value(raw))
+}
+
+// periodShare returns a value's percentage of its period total, or 0 for empty periods.
+func periodShare(value, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(value) / float64(total) * 100
+}
+
+// viewSeries returns the values of a language for each period in the view selected by --view. In the share
+// view, periods without repositories are NaN so that the graph leaves a gap rather than dropping to 0%.
+// The cumulative view also counts the periods before the first one displayed.
+func viewSeries(languageMapPerPeriod map[int]map[string]int, totalsPerPeriod map[int]int, periods []int, lang string, opts trendOptions) []float64 {
+	series := make([]float64, len(periods))
+	switch opts.View {
+	case "share":
+		for i, period := range periods {
+			if totalsPerPeriod[period] == 0 {
+				series[i] = math.NaN()
+				continue
+			}
+			series[i] = periodShare(languageMapPerPeriod[period][lang], totalsPerPeriod[period])
+		}
+	case "cumulative":
+		var running int
+		for period, langMap := range languageMapPerPeriod {
+			if period < periods[0] {
+				running += langMap[lang]
+			}
+		}
+		for i, period := range periods {
+			running += languageMapPerPeriod[period][lang]
+			series[i] = opts.value(running)
+		}
+	default:
+		for i, period := range periods {
+			series[i] = opts.value(languageMapPerPeriod[period][lang])
+		}
+	}
+	return series
+}
+
+// trendForecast is the forecast of a language with the value of the last displayed period it starts from.
+type trendForecast struct {
+	// Start is the last period's value as used by the fit: scaled up by --partial annualize, or the fitted value
+	// when the period was left out of the fit.
+	Start  float64
+	Points []forecastPoint
+}
+
+// trendForecasts fits each language's series in the selected view and forecasts opts.Forecast periods after
+// the last one displayed. When the last period is still in progress, --partial either leaves it out of the fit
+// or scales it up to a full period. Languages with fewer than three periods of data are not forecast.
+func trendForecasts(languageMapPerPeriod map[int]map[string]int, totalsPerPeriod map[int]int, periods []int, langs []string, opts trendOptions) map[string]trendForecast {
+	now := time.Now()
+	last := periods[len(periods)-1]
+	partial := last == periodOf(now, opts.Interval)
+	fraction := elapsedFraction(last, opts.Interval, now)
+	if partial {
+		if opts.Partial == "annualize" {
+			pterm.Info.Println(fmt.Sprintf("Forecast annualizes the current partial %s (%s, %.0f%% elapsed)", opts.Interval, periodLabel(last, opts.Interval), fraction*100))
+		} else {
+			pterm.Info.Println(fmt.Sprintf("Forecast excludes the current partial %s (%s)", opts.Interval, periodLabel(last, opts.Interval)))
+		}
+	}
+
+	// Shares cannot exceed 100%, and no value can be negative.
+	max := math.Inf(1)
+	if opts.View == "share" {
+		max = 100
+	}
+
+	forecasts := make(map[string]trendForecast)
+	for _, lang := range langs {
+		series := viewSeries(languageMapPerPeriod, totalsPerPeriod, periods, lang, opts)
+		if partial {
+			i := len(series) - 1
+			switch {
+			case opts.Partial != "annualize" || fraction == 0:
+				series[i] = math.NaN()
+			case opts.View == "absolute":
+				series[i] /= fraction
+			case opts.View == "cumulative":
+				// Only the partial period's increment is scaled up, not the running total before it.
+				increment := opts.value(languageMapPerPeriod[last][lang])
+				series[i] += increment/fraction - increment
+			}
+			// Shares are ratios within the period, so they need no annualizing.
+		}
+		points, ok := forecastSeries(series, opts.Forecast, 0, max)
+		if !ok {
+			continue
+		}
+		start := series[len(series)-1]
+		if math.IsNaN(start) {
+			fit, _ := fitLinear(series)
+			start = fit.predict(float64(len(series)-1), 0, max).Value
+		}
+		forecasts[lang] = trendForecast{Start: start, Points: points}
+	}
+	if len(forecasts) < len(langs) {
+		pterm.Warning.Println("Some languages were not forecast, since a forecast needs at least 3 periods of data")
+	}
+	return forecasts
+}
+
+// renderForecastTable displays the forecast of each plotted language per period, with its 95% prediction interval.
+func renderForecastTable(periods []int, langs []string, forecasts map[string]trendForecast, opts trendOptions) {
+	pterm.DefaultSection.Println(fmt.Sprintf("Forecast (%s, Linear Fit with 95%% Prediction Interval)", opts.viewName()))
+
+	last := periods[len(periods)-1]
+	header := []string{"Language"}
+	for i := 1; i <= opts.Forecast; i++ {
+		header = append(header, periodLabel(last+i, opts.Interval)+" (forecast)")
+	}
+	rows := [][]string{header}
+
+	precision := opts.viewPrecision()
+	for _, lang := range langs {
+		forecast, ok := forecasts[lang]
+		if !ok {
+			continue
+		}
+		row := []string{lang}
+		for _, point := range forecast.Points {
+			row = append(row, pterm.Gray(fmt.Sprintf("%.*f (%.*f–%.*f)", precision, point.Value, precision, point.Low, precision, point.High)))
+		}
+		rows = append(rows, row)
+	}
+
+	pterm.DefaultTable.WithHasHeader(true).WithData(rows).Render()
+	pterm.Println()
+}
+
+// viewPrecision returns the number of decimals used to plot the selected view.
+func (o trendOptions) viewPrecision() int {
+	if o.View == "share" {
+		return 1
+	}
+	return o.precision()
+}
+
+// viewName describes the plotted values for the graph title (e.g., Share of Repos (%)).
+func (o trendOptions) viewName() string {
+	switch o.View {
+	case "share":
+		if o.Metric == "bytes" {
+			return "Share of Bytes (%)"
+		}
+		return "Share of Repos (%)"
+	case "cumulative":
+		return "Cumulative " + o.metricName()
+	}
+	return o.metricName()
+}
+
+// metricName describes the displayed values for titles and table headers.
+func (o trendOptions) metricName() string {
+	if o.Metric == "bytes" {
+		return intervalName(o.Unit)
+	}
+	return "Repo Count"
 }
 
 var trendCmd = &cobra.Command{
@@ -32,16 +274,18 @@ var trendCmd = &cobra.Command{
 }
 
 // trendIndicator returns a colored arrow symbol and signed change string
-// representing the year-over-year direction for a language.
-func trendIndicator(current, previous int) (string, string) {
+// representing the period-over-period direction for a language. The change
+// is formatted with the given number of decimals and suffix (e.g., "pp").
+func trendIndicator(current, previous float64, decimals int, suffix string) (string, string) {
 	diff := current - previous
+	formatted := fmt.Sprintf("%.*f", decimals, diff)
 	switch {
+	case formatted == fmt.Sprintf("%.*f", decimals, 0.0) || formatted == fmt.Sprintf("-%.*f", decimals, 0.0):
+		return pterm.Gray("●"), pterm.Gray("0" + suffix)
 	case diff > 0:
-		return pterm.Green("▲"), pterm.Green(fmt.Sprintf("+%d", diff))
-	case diff < 0:
-		return pterm.Red("▼"), pterm.Red(fmt.Sprintf("%d", diff))
+		return pterm.Green("▲"), pterm.Green("+" + formatted + suffix)
 	default:
-		return pterm.Gray("●"), pterm.Gray("0")
+		return pterm.Red("▼"), pterm.Red(formatted + suffix)
 	}
 }
 
@@ -59,7 +303,13 @@ func topLanguageNames(trendData map[string]int, language string, top int) []stri
 		}
 		sorted = append(sorted, langCount{lang, count})
 	}
-	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Count > sorted[j].Count })
+	// Break ties by name so that the top N languages are the same on every run.
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Language < sorted[j].Language
+	})
 
 	limit := len(sorted)
 	if top > 0 && top < limit {
@@ -88,117 +338,227 @@ func graphColors() []asciigraph.AnsiColor {
 	}
 }
 
+// graphForecastColors returns darker shades of graphColors, in the same order, for forecast series.
+func graphForecastColors() []asciigraph.AnsiColor {
+	return []asciigraph.AnsiColor{
+		asciigraph.DarkRed,
+		asciigraph.DarkGreen,
+		asciigraph.DarkGoldenrod,
+		asciigraph.DarkBlue,
+		asciigraph.DarkCyan,
+		asciigraph.DarkGray,
+		asciigraph.Chocolate,
+		asciigraph.DarkSlateBlue,
+		asciigraph.DarkSalmon,
+		asciigraph.DarkOliveGreen,
+	}
+}
+
 func runTrend(cmd *cobra.Command, args []string) error {
 	org := org_flag
 	enterprise := enterprise_flag
 	repoLimit := repo_limit_flag
 	orgLimit := org_limit_flag
-	top := top_flag
-	language := language_flag
 	hostname := github_enterprise_server_url_flag
 
 	if err := ValidateFlags(org, enterprise); err != nil {
 		return err
 	}
 
+	pipeline, err := LanguagePipelineFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
 	if min_year_flag > 0 && max_year_flag > 0 && min_year_flag > max_year_flag {
 		return fmt.Errorf("--min-year (%d) cannot be greater than --max-year (%d)", min_year_flag, max_year_flag)
 	}
 
-	var orgs []string
-
-	if enterprise != "" {
-		// Determine the language filter or top languages info based on flags.
-		languageFilter := GetLanguageFilter(codeql_flag, language, top)
-		// Print organization and repository limits along with the language filter.
-		PrintInfoWithFormat("Organization limit: %d, Repository limit: %d, %s", orgLimit, repoLimit, languageFilter)
-		spinnerEnterprise, _ := StartIndexingEnterpriseSpinner(enterprise)
-		var err error
-		orgs, err = FetchOrganizations(enterprise, orgLimit, hostname)
-		if err != nil {
-			spinnerEnterprise.Fail("Failed to index organizations for enterprise")
+	if !MatchesLanguageFilter(interval_flag, TREND_INTERVALS) {
+		return fmt.Errorf("invalid interval specified. Options are: %s", strings.Join(TREND_INTERVALS, ", "))
+	}
+	if !MatchesLanguageFilter(date_field_flag, TREND_DATE_FIELDS) {
+		return fmt.Errorf("invalid date field specified. Options are: %s", strings.Join(TREND_DATE_FIELDS, ", "))
+	}
+	if !MatchesLanguageFilter(view_flag, TREND_VIEWS) {
+		return fmt.Errorf("invalid view specified. Options are: %s", strings.Join(TREND_VIEWS, ", "))
+	}
+	if !MatchesLanguageFilter(metric_flag, TREND_METRICS) {
+		return fmt.Errorf("invalid metric specified. Options are: %s", strings.Join(TREND_METRICS, ", "))
+	}
+	if forecast_flag < 0 {
+		return fmt.Errorf("--forecast cannot be negative")
+	}
+	if !MatchesLanguageFilter(partial_flag, TREND_PARTIAL_MODES) {
+		return fmt.Errorf("invalid partial mode specified. Options are: %s", strings.Join(TREND_PARTIAL_MODES, ", "))
+	}
+	var benchmark *Benchmark
+	if benchmark_flag != "" {
+		if benchmark, err = LoadBenchmark(benchmark_flag, benchmark_economy_flag); err != nil {
 			return err
 		}
-		spinnerEnterprise.Success(fmt.Sprintf("Successfully indexed enterprise: %s", enterprise))
-		PrintTotalOrganizations(len(orgs))
+	}
+	unit, _ := cmd.Flags().GetString("unit")
+	if err := ValidateUnit(unit); err != nil {
+		return err
+	}
+	opts := trendOptions{Interval: interval_flag, DateField: date_field_flag, Metric: metric_flag, Unit: unit, View: view_flag, Forecast: forecast_flag, Partial: partial_flag, Benchmark: benchmark, Top: pipeline.Top}
+
+	// Resolve the first and last periods to include. A zero bound is open.
+	var firstPeriod, lastPeriod int
+	if since_flag != "" {
+		if firstPeriod, err = parsePeriodBound(since_flag, opts.Interval, false); err != nil {
+			return fmt.Errorf("--since: %v", err)
+		}
+	} else if min_year_flag > 0 {
+		firstPeriod, _ = yearPeriods(min_year_flag, opts.Interval)
+	}
+	if until_flag != "" {
+		if lastPeriod, err = parsePeriodBound(until_flag, opts.Interval, true); err != nil {
+			return fmt.Errorf("--until: %v", err)
+		}
+	} else if opts.Interval == "year" || cmd.Flags().Changed("max-year") {
+		if max_year_flag > 0 {
+			_, lastPeriod = yearPeriods(max_year_flag, opts.Interval)
+		}
 	} else {
-		// Handle the case where only a single organization is provided.
-		topLanguagesInfo := GetLanguageFilter(codeql_flag, language, top)
-		PrintInfoWithFormat("Repository limit: %d, %s", repoLimit, topLanguagesInfo)
-		orgs = []string{org}
+		// Default to the last complete quarter or month, mirroring --max-year defaulting to last year.
+		lastPeriod = periodOf(time.Now(), opts.Interval) - 1
+	}
+	if firstPeriod > 0 && lastPeriod > 0 && firstPeriod > lastPeriod {
+		return fmt.Errorf("the first period (%s) cannot be after the last period (%s)", periodLabel(firstPeriod, opts.Interval), periodLabel(lastPeriod, opts.Interval))
+	}
+
+	sampler, err := SamplerFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	opts.Sampler = sampler
+
+	order, err := ParseRepositoryOrder(order_by_flag, order_flag)
+	if err != nil {
+		return err
+	}
+
+	sizeFilter, err := NewSizeFilter(min_size_flag, max_size_flag)
+	if err != nil {
+		return err
+	}
+
+	orgFilter, err := ParseOrgFilter(org_include_flag, org_exclude_flag, orgs_file_flag)
+	if err != nil {
+		return err
+	}
+
+	// Describe the language filters in the order they are applied.
+	languageFilter := pipeline.String()
+	if !sizeFilter.IsEmpty() {
+		languageFilter += ", " + sizeFilter.String()
+	}
+	if !order.IsEmpty() {
+		languageFilter += ", " + order.String()
+	}
+	if sampler != nil {
+		languageFilter += ", " + sampler.String()
+	}
+	orgs, err := ResolveOrganizations(enterprise, org, orgLimit, repoLimit, languageFilter, orgFilter, hostname)
+	if err != nil {
+		return err
+	}
+
+	// Draw the sample from the repositories of all organizations at once, so that it can be pooled.
+	if sampler != nil {
+		if err := sampler.Plan(orgs, hostname); err != nil {
+			return err
+		}
 	}
 
-	// Initialize a map to store language data per year.
-	languageMapPerYear := make(map[int]map[string]int)
+	// Initialize a map to store language data per period.
+	languageMapPerPeriod := make(map[int]map[string]int)
 
-	// Initialize a map to store number of repos per year.
-	reposPerYear := make(map[int]int)
+	// Initialize a map to store number of repos per period.
+	reposPerPeriod := make(map[int]int)
+
+	// Initialize a map to store the denominator of each period's percentages: repos, or bytes with --metric bytes.
+	totalsPerPeriod := make(map[int]int)
 
 	// Initialize trendData as a map to store language trends.
 	trendData := make(map[string]int)
 
 	var totalRepos int
+	var undatedRepos int
 
-	// Iterate over each organization to fetch repositories and analyze languages.
-	for orgIndex, org := range orgs {
-		// Start a spinner to indicate progress for indexing the organization.
-		spinnerInfo, _ := pterm.DefaultSpinner.Start(fmt.Sprintf("Indexing organization: %s", org))
+	// Keep the analyzed repositories for --save.
+	var savedRepos []Repository
 
-		// First, count the total number of repositories in the organization
-		totalReposInOrg, err := CountRepositoriesGraphQL(org, hostname)
+	// Repositories are sampled by page number, which is only available through the REST API.
+	var client *api.RESTClient
+	if sampler != nil {
+		client, err = CreateRESTClient(hostname)
 		if err != nil {
-			// Stop the spinner and indicate failure if an error occurs.
-			spinnerInfo.Fail("Failed to index organization")
+			pterm.Error.Println("Failed to create REST client:", err)
 			return err
 		}
+	}
 
-		if totalReposInOrg == 0 {
-			// Stop the spinner and indicate a warning if no repositories are found.
-			spinnerInfo.Warning(fmt.Sprintf("No repositories found for organization %d of %d: %s", orgIndex+1, len(orgs), org))
-			continue
-		}
-
-		// Apply the repo limit to determine effective repository count
-		effectiveRepoCount := totalReposInOrg
-		if repoLimit < totalReposInOrg {
-			effectiveRepoCount = repoLimit
-		}
-
-		// Stop the spinner and indicate success.
-		spinnerInfo.Success(fmt.Sprintf("Successfully indexed organization %d of %d: %s (%d repositories, limited to %d)", orgIndex+1, len(orgs), org, totalReposInOrg, effectiveRepoCount))
-
-		// Fetch repositories with languages using GraphQL API with progress bar.
-		repos, err := FetchRepositoriesGraphQL(org, repoLimit, totalReposInOrg, hostname)
+	// Iterate over each organization to fetch repositories and analyze languages.
+	for orgIndex, org := range orgs {
+		// Count and fetch the repositories of the organization with their languages.
+		repos, err := LoadOrganizationRepositories(client, org, orgIndex, len(orgs), repoLimit, order, sampler, hostname)
 		if err != nil {
 			return err
 		}
+		if len(repos) == 0 {
+			continue
+		}
+
+		// Drop repositories outside the --min-size and --max-size range.
+		repos = sizeFilter.Apply(repos)
 
 		// Increment the total repository count.
 		totalRepos += len(repos)
+		savedRepos = append(savedRepos, repos...)
 
-		// Analyze each repository for language usage and group by year.
+		// Analyze each repository for language usage and group by period.
 		for _, repo := range repos {
-			// Update the trend data map with the fetched data by incrementing the count.
-			for lang := range repo.Languages {
-				trendData[lang]++
+			// Only count the primary language with --primary, otherwise languages that pass the filters and threshold.
+			repoLanguages := pipeline.RepositoryLanguages(repo)
+
+			// Repositories that are not archived have no archived date, so they are left out of the periods.
+			timestamp := repositoryDate(repo, opts.DateField)
+			if timestamp == "" {
+				undatedRepos++
+				continue
 			}
 
-			// Parse the repository's creation date
-			createdAt, err := time.Parse(GITHUB_TIMESTAMP_LAYOUT, repo.CreatedAt)
+			// Parse the repository's date selected by --date-field
+			date, err := time.Parse(GITHUB_TIMESTAMP_LAYOUT, timestamp)
 			if err != nil {
-				pterm.Warning.Println(fmt.Sprintf("Skipping repository %s due to invalid creation date: %s", repo.Name, err))
+				pterm.Warning.Println(fmt.Sprintf("Skipping repository %s due to invalid %s date: %s", repo.Name, opts.DateField, err))
 				continue
 			}
 
-			// Group the language data by year. This requires extracting the year from the repository's creation date.
-			creationYear := createdAt.Year()
-			reposPerYear[creationYear]++
-			if languageMapPerYear[creationYear] == nil {
-				languageMapPerYear[creationYear] = make(map[string]int)
+			// Group the language data by the period containing the repository's date.
+			repoPeriod := periodOf(date, opts.Interval)
+
+			// Rank the top languages over the plotted periods only: the repo count, or the bytes with --metric bytes.
+			if (firstPeriod == 0 || repoPeriod >= firstPeriod) && (lastPeriod == 0 || repoPeriod <= lastPeriod) {
+				for lang := range repoLanguages {
+					trendData[lang] += opts.weight(repo, lang)
+				}
+			}
+			reposPerPeriod[repoPeriod]++
+			if opts.Metric == "bytes" {
+				totalsPerPeriod[repoPeriod] += repo.TotalSize
+			} else {
+				totalsPerPeriod[repoPeriod]++
+			}
+			if languageMapPerPeriod[repoPeriod] == nil {
+				languageMapPerPeriod[repoPeriod] = make(map[string]int)
 			}
 
-			for lang := range repo.Languages {
-				languageMapPerYear[creationYear][lang]++
+			for lang := range repoLanguages {
+				languageMapPerPeriod[repoPeriod][lang] += opts.weight(repo, lang)
 			}
 		}
 	}
@@ -206,67 +566,101 @@ func runTrend(cmd *cobra.Command, args []string) error {
 	// Print the total number of repositories analyzed.
 	pterm.Println()
 	pterm.Info.Println(fmt.Sprintf("Total number of repositories analyzed: %d", totalRepos))
-	pterm.Println()
-
-	// Extract years and sort in ascending order (oldest first).
-	years := make([]int, 0, len(languageMapPerYear))
-	for year := range languageMapPerYear {
-		years = append(years, year)
+	if sampler != nil {
+		pterm.Info.Println(fmt.Sprintf("Sampled %d of %d repositories (seed %d). Percentages of repos are estimates with 95%% confidence intervals", sampler.Sampled, sampler.Population, sampler.Seed))
 	}
-	sort.Ints(years)
-
-	// Filter years based on min-year and max-year flags.
-	if min_year_flag > 0 || max_year_flag > 0 {
-		filtered := make([]int, 0, len(years))
-		for _, y := range years {
-			if min_year_flag > 0 && y < min_year_flag {
-				continue
-			}
-			if max_year_flag > 0 && y > max_year_flag {
-				continue
-			}
-			filtered = append(filtered, y)
-		}
-		years = filtered
+	PrintSizeFilterSummary(sizeFilter)
+	if err := SaveSnapshot("trend", hostname, enterprise, orgs, savedRepos, sampler); err != nil {
+		return err
 	}
-
-	if codeql_flag {
-		for year, langMap := range languageMapPerYear {
-			languageMapPerYear[year] = IsCodeQLLanguage(langMap)
-		}
+	// Print the language threshold so that results remain comparable across runs.
+	if thresholdInfo := GetThresholdInfo(min_bytes_flag, min_share_flag); thresholdInfo != "" {
+		pterm.Info.Println(thresholdInfo)
+	}
+	pipeline.WarnUnknownTypes()
+	// In primary mode each repository contributes to exactly one language.
+	if primary_flag {
+		pterm.Info.Println("Primary language mode: each repository counts toward its primary language only")
+	}
+	if undatedRepos > 0 {
+		pterm.Info.Println(fmt.Sprintf("Repositories with no %s date (not included in the trend): %d", opts.DateField, undatedRepos))
 	}
+	if benchmark != nil {
+		pterm.Info.Println(fmt.Sprintf("Benchmark: GitHub Innovation Graph (%s), share of developers pushing code in the quarters of each period", benchmark.Name()))
+		pterm.Info.Println(BenchmarkBasis(opts.Metric))
+	}
+	pterm.Println()
+
+	// List every period from the oldest to the newest within the bounds, in ascending order (oldest first).
+	// Periods without repositories are kept so that the graph and period-over-period changes stay evenly spaced.
+	periods := trendPeriods(reposPerPeriod, firstPeriod, lastPeriod)
 
-	// Determine the top languages to focus on.
-	topLangs := topLanguageNames(trendData, language, top)
+	// Determine the top languages to focus on. The languages were filtered per repository, so only the top N remain to be selected.
+	topLangs := topLanguageNames(trendData, "", pipeline.Top)
 
 	// ── Section 1: Multi-series Line Graph ──────────────────────────
 	// ASCII line chart showing how each top language's count changes over time.
-	if len(years) >= 2 {
-		renderLineGraph(languageMapPerYear, years, topLangs)
+	// Forecast the plotted languages when --forecast is set.
+	var graphLangs []string
+	var forecasts map[string]trendForecast
+	if len(periods) > 0 {
+		graphLangs = graphLanguages(languageMapPerPeriod[periods[len(periods)-1]], topLangs)
+		if opts.Forecast > 0 {
+			forecasts = trendForecasts(languageMapPerPeriod, totalsPerPeriod, periods, graphLangs, opts)
+		}
+	}
+	if len(periods) >= 2 {
+		renderLineGraph(languageMapPerPeriod, totalsPerPeriod, periods, graphLangs, forecasts, opts)
+	}
+	if len(forecasts) > 0 {
+		renderForecastTable(periods, graphLangs, forecasts, opts)
 	}
 
-	// ── Section 2: Year-by-Year Detail Tables ───────────────────────
-	// Detailed per-year tables with trend indicators compared to the prior year.
-	renderYearTables(languageMapPerYear, years, topLangs, reposPerYear, language, top)
+	// ── Section 2: Period-by-Period Detail Tables ───────────────────
+	// Detailed per-period tables with trend indicators compared to the prior period.
+	renderPeriodTables(languageMapPerPeriod, periods, reposPerPeriod, totalsPerPeriod, opts)
 
 	return nil
 }
 
-// renderLineGraph displays a multi-series ASCII line graph showing language trends over time.
-func renderLineGraph(languageMapPerYear map[int]map[string]int, years []int, topLangs []string) {
-	pterm.DefaultSection.Println("Language Trends Over Time (Repo Count Created by Year)")
-
-	// Rank languages by their count in the max (last) year, descending.
-	maxYear := years[len(years)-1]
-	maxYearData := languageMapPerYear[maxYear]
+// trendPeriods returns every period between the oldest and newest period with repositories, clipped to
+// the given bounds (zero bounds are open), in ascending order.
+func trendPeriods(reposPerPeriod map[int]int, firstPeriod, lastPeriod int) []int {
+	oldest, newest := 0, 0
+	for period := range reposPerPeriod {
+		if firstPeriod > 0 && period < firstPeriod {
+			continue
+		}
+		if lastPeriod > 0 && period > lastPeriod {
+			continue
+		}
+		if oldest == 0 || period < oldest {
+			oldest = period
+		}
+		if period > newest {
+			newest = period
+		}
+	}
+	if oldest == 0 {
+		return nil
+	}
+	periods := make([]int, 0, newest-oldest+1)
+	for period := oldest; period <= newest; period++ {
+		periods = append(periods, period)
+	}
+	return periods
+}
 
+// graphLanguages returns the languages to plot: the top languages ranked by their value in the last period,
+// descending, limited to MAX_GRAPH_SERIES.
+func graphLanguages(lastPeriodData map[string]int, topLangs []string) []string {
 	type langCount struct {
 		Language string
 		Count    int
 	}
 	ranked := make([]langCount, 0, len(topLangs))
 	for _, lang := range topLangs {
-		ranked = append(ranked, langCount{lang, maxYearData[lang]})
+		ranked = append(ranked, langCount{lang, lastPeriodData[lang]})
 	}
 	sort.Slice(ranked, func(i, j int) bool { return ranked[i].Count > ranked[j].Count })
 
@@ -278,58 +672,123 @@ func renderLineGraph(languageMapPerYear map[int]map[string]int, years []int, top
 	for i := 0; i < maxSeries; i++ {
 		langs[i] = ranked[i].Language
 	}
+	return langs
+}
 
-	// Build data series: each series is a slice of float64 counts per year (ascending).
-	allSeries := make([][]float64, len(langs))
-	for i, lang := range langs {
-		series := make([]float64, len(years))
-		for j, year := range years {
-			series[j] = float64(languageMapPerYear[year][lang])
+// forecastGraphSeries returns a forecast series to plot, blank over the displayed periods except the last one,
+// from which it starts at the forecast's start value, followed by a value of each forecast point.
+func forecastGraphSeries(forecast trendForecast, periods, points int, value func(forecastPoint) float64) []float64 {
+	series := make([]float64, points)
+	for j := range series {
+		switch {
+		case j < periods-1:
+			series[j] = math.NaN()
+		case j == periods-1:
+			series[j] = forecast.Start
+		default:
+			series[j] = value(forecast.Points[j-periods])
 		}
-		allSeries[i] = series
 	}
+	return series
+}
+
+// renderLineGraph displays a multi-series ASCII line graph showing language trends over time. Forecasts, if any,
+// are drawn as separate series in a darker shade of each language's color, together with their prediction interval
+// bounds, starting from the last value used by the fit.
+func renderLineGraph(languageMapPerPeriod map[int]map[string]int, totalsPerPeriod map[int]int, periods []int, langs []string, forecasts map[string]trendForecast, opts trendOptions) {
+	pterm.DefaultSection.Println(fmt.Sprintf("Language Trends Over Time (%s %s by %s)", opts.viewName(), dateFieldName(opts.DateField), intervalName(opts.Interval)))
 
-	// Build x-axis label caption showing first and last year.
-	caption := fmt.Sprintf("%d → %d", years[0], years[len(years)-1])
+	// Only extend the x-axis when there is something to forecast.
+	forecastPeriods := 0
+	if len(forecasts) > 0 {
+		forecastPeriods = opts.Forecast
+	}
+	points := len(periods) + forecastPeriods
 
+	// Build data series: each series is a slice of float64 values per period (ascending) in the selected view.
+	// Actual series are padded with NaN over the forecast periods, which asciigraph leaves blank.
 	colors := graphColors()
-	seriesColors := make([]asciigraph.AnsiColor, len(langs))
-	for i := range langs {
-		seriesColors[i] = colors[i%len(colors)]
+	forecastColors := graphForecastColors()
+	var allSeries [][]float64
+	var seriesColors []asciigraph.AnsiColor
+	var legends []string
+	for i, lang := range langs {
+		series := viewSeries(languageMapPerPeriod, totalsPerPeriod, periods, lang, opts)
+		actual := make([]float64, points)
+		copy(actual, series)
+		for j := len(series); j < points; j++ {
+			actual[j] = math.NaN()
+		}
+		allSeries = append(allSeries, actual)
+		seriesColors = append(seriesColors, colors[i%len(colors)])
+		legends = append(legends, lang)
+	}
+	for i, lang := range langs {
+		forecast, ok := forecasts[lang]
+		if !ok {
+			continue
+		}
+		allSeries = append(allSeries, forecastGraphSeries(forecast, len(periods), points, func(point forecastPoint) float64 { return point.Value }))
+		seriesColors = append(seriesColors, forecastColors[i%len(forecastColors)])
+		legends = append(legends, lang+" (forecast)")
+	}
+	// The prediction interval bounds come last, since only the series before them have a legend.
+	for i, lang := range langs {
+		forecast, ok := forecasts[lang]
+		if !ok {
+			continue
+		}
+		allSeries = append(allSeries,
+			forecastGraphSeries(forecast, len(periods), points, func(point forecastPoint) float64 { return point.Low }),
+			forecastGraphSeries(forecast, len(periods), points, func(point forecastPoint) float64 { return point.High }))
+		seriesColors = append(seriesColors, forecastColors[i%len(forecastColors)], forecastColors[i%len(forecastColors)])
+	}
+
+	// Build x-axis label caption showing first and last period.
+	caption := fmt.Sprintf("%s → %s", periodLabel(periods[0], opts.Interval), periodLabel(periods[len(periods)-1], opts.Interval))
+	if forecastPeriods > 0 {
+		caption += fmt.Sprintf(" (forecast with 95%% prediction interval → %s)", periodLabel(periods[len(periods)-1]+forecastPeriods, opts.Interval))
+	}
+
+	width := points * 3
+	if width > MAX_GRAPH_WIDTH {
+		width = MAX_GRAPH_WIDTH
 	}
 
 	graph := asciigraph.PlotMany(allSeries,
 		asciigraph.Height(15),
-		asciigraph.Width(len(years)*3),
-		asciigraph.Precision(0),
+		asciigraph.Width(width),
+		asciigraph.Precision(uint(opts.viewPrecision())),
 		asciigraph.Caption(caption),
 		asciigraph.SeriesColors(seriesColors...),
-		asciigraph.SeriesLegends(langs...),
+		asciigraph.SeriesLegends(legends...),
 	)
 	pterm.Println(graph)
 	pterm.Println()
 }
 
-// renderYearTables displays detailed per-year tables with trend indicators.
-func renderYearTables(languageMapPerYear map[int]map[string]int, years []int, topLangs []string, reposPerYear map[int]int, language string, top int) {
-	pterm.DefaultSection.Println("Year-by-Year Breakdown")
-
-	// Iterate years in descending order for the detail tables.
-	for idx := len(years) - 1; idx >= 0; idx-- {
-		year := years[idx]
-		yearRepoCount := reposPerYear[year]
-		pterm.DefaultSection.WithLevel(2).Println(fmt.Sprintf("Year: %d (%d repos)", year, yearRepoCount))
+// renderPeriodTables displays detailed per-period tables with trend indicators.
+// Percentages are shares of the period's repos (or bytes with --metric bytes), and their change is reported in percentage points.
+func renderPeriodTables(languageMapPerPeriod map[int]map[string]int, periods []int, reposPerPeriod map[int]int, totalsPerPeriod map[int]int, opts trendOptions) {
+	name := intervalName(opts.Interval)
+	pterm.DefaultSection.Println(fmt.Sprintf("%s-by-%s Breakdown", name, name))
+
+	// Iterate periods in descending order for the detail tables.
+	for idx := len(periods) - 1; idx >= 0; idx-- {
+		period := periods[idx]
+		periodRepoCount := reposPerPeriod[period]
+		// Skip the empty periods that were only kept to evenly space the graph.
+		if periodRepoCount == 0 {
+			continue
+		}
+		pterm.DefaultSection.WithLevel(2).Println(fmt.Sprintf("%s: %s (%d repos)", name, periodLabel(period, opts.Interval), periodRepoCount))
 
 		sortedLanguages := make([]struct {
 			Language string
 			Count    int
-		}, 0, len(languageMapPerYear[year]))
+		}, 0, len(languageMapPerPeriod[period]))
 
-		languages := ParseLanguages(language)
-		for lang, count := range languageMapPerYear[year] {
-			if len(languages) > 0 && !MatchesLanguageFilter(lang, languages) {
-				continue
-			}
+		for lang, count := range languageMapPerPeriod[period] {
 			sortedLanguages = append(sortedLanguages, struct {
 				Language string
 				Count    int
@@ -340,31 +799,55 @@ func renderYearTables(languageMapPerYear map[int]map[string]int, years []int, to
 			return sortedLanguages[i].Count > sortedLanguages[j].Count
 		})
 
-		rows := [][]string{{"Language", "Count", "Percentage", "Trend", "YoY Change"}}
+		changeLabel := intervalChangeLabel(opts.Interval)
+		header := []string{"Language", "Count", "Percentage", "Trend", fmt.Sprintf("%s Change", changeLabel), fmt.Sprintf("%s Change (pp)", changeLabel)}
+		if opts.Metric == "bytes" {
+			header = []string{"Language", intervalName(opts.Unit), "Share", "Trend", fmt.Sprintf("%s Change", changeLabel), fmt.Sprintf("%s Share Change (pp)", changeLabel)}
+		}
+		// Compare against the Innovation Graph quarters covered by the period when --benchmark is set.
+		// Benchmark shares are taken of the period's whole language mix, before the top N are selected.
+		firstQuarter, lastQuarter := PeriodQuarters(period, opts.Interval)
+		mixTotal := LanguageMixTotal(languageMapPerPeriod[period])
+		if opts.Benchmark != nil {
+			header = append(header, "Mix Share", fmt.Sprintf("%s Share", opts.Benchmark.Name()), "Index")
+		}
+		rows := [][]string{header}
 		for i, langData := range sortedLanguages {
-			if top > 0 && i >= top {
+			if opts.Top > 0 && i >= opts.Top {
 				break
 			}
-			percentage := 0
-			if yearRepoCount > 0 {
-				percentage = int(float64(langData.Count) / float64(yearRepoCount) * 100)
+			percentage := FormatPercentage(langData.Count, totalsPerPeriod[period], primary_flag || opts.Metric == "bytes")
+			// Shares of repos estimated from a sample show their 95% confidence interval.
+			if opts.Sampler != nil && opts.Metric == "repos" {
+				percentage = opts.Sampler.FormatPercentageCI(langData.Count, totalsPerPeriod[period], primary_flag)
 			}
 
 			arrow := ""
 			change := ""
+			shareChange := ""
 			if idx > 0 {
-				prevYear := years[idx-1]
-				prevCount := languageMapPerYear[prevYear][langData.Language]
-				arrow, change = trendIndicator(langData.Count, prevCount)
+				prevPeriod := periods[idx-1]
+				prevCount := languageMapPerPeriod[prevPeriod][langData.Language]
+				arrow, change = trendIndicator(opts.value(langData.Count), opts.value(prevCount), opts.precision(), "")
+				// A share change is meaningless against an empty period, so it is only shown when the prior period has repos.
+				if totalsPerPeriod[prevPeriod] > 0 {
+					_, shareChange = trendIndicator(periodShare(langData.Count, totalsPerPeriod[period]), periodShare(prevCount, totalsPerPeriod[prevPeriod]), 1, "pp")
+				}
 			}
 
-			rows = append(rows, []string{
+			row := []string{
 				langData.Language,
-				fmt.Sprintf("%d", langData.Count),
-				fmt.Sprintf("%d%%", percentage),
+				opts.format(langData.Count),
+				percentage,
 				arrow,
 				change,
-			})
+				shareChange,
+			}
+			if opts.Benchmark != nil {
+				mixShare, benchmarkShare, index := opts.Benchmark.benchmarkColumns(langData.Language, langData.Count, mixTotal, firstQuarter, lastQuarter)
+				row = append(row, mixShare, benchmarkShare, index)
+			}
+			rows = append(rows, row)
 		}
 
 		pterm.DefaultTable.WithHasHeader(true).WithData(rows).Render()